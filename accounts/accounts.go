@@ -0,0 +1,308 @@
+package accounts
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Role string
+
+const (
+	Coach     Role = "coach"
+	Athlete   Role = "athlete"
+	Organiser Role = "organiser" // Runs competitions, has no squad
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUsernameTaken      = errors.New("username already taken")
+	ErrUnknownUser        = errors.New("unknown user")
+)
+
+// User is a local account. An athlete shares their routines with the coaches
+// listed in Coaches; a coach's squad is every athlete listing them.
+type User struct {
+	Username   string    `json:"username"`
+	Role       Role      `json:"role"`
+	Coaches    []string  `json:"coaches"`
+	Created    time.Time `json:"created"`
+	Salt       []byte    `json:"salt"`
+	Hash       []byte    `json:"hash"`
+	Iterations int       `json:"iterations"`
+}
+
+// Password hashing is PBKDF2-HMAC-SHA256 with a random salt per user. The
+// iteration count is stored with each hash so it can be raised later.
+const (
+	hashIterations = 600_000
+	hashLength     = 32
+	saltLength     = 16
+
+	MinPasswordLength = 8
+	SessionLifetime   = 30 * 24 * time.Hour
+)
+
+var validUsername = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,31}$`)
+
+type session struct {
+	username string
+	expires  time.Time
+}
+
+// Registry holds the accounts and their sessions. Accounts are written to
+// path after every change if it is set; sessions only live in memory, so a
+// restart signs everyone out.
+type Registry struct {
+	mu       sync.RWMutex
+	path     string
+	users    map[string]*User
+	sessions map[string]session
+}
+
+// NewRegistry returns a registry that keeps accounts in memory only.
+func NewRegistry() *Registry {
+	return &Registry{users: map[string]*User{}, sessions: map[string]session{}}
+}
+
+// OpenRegistry returns a registry saved to a JSON file, loading the accounts
+// already in it.
+func OpenRegistry(path string) (*Registry, error) {
+	registry := NewRegistry()
+	registry.path = path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var users []*User
+	err = json.Unmarshal(data, &users)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	for _, user := range users {
+		registry.users[user.Username] = user
+	}
+	return registry, nil
+}
+
+// save writes every account to the registry file. Callers hold mu.
+func (registry *Registry) save() error {
+	if registry.path == "" {
+		return nil
+	}
+	users := make([]*User, 0, len(registry.users))
+	for _, user := range registry.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(registry.path), ".accounts-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), registry.path)
+}
+
+// --- Accounts ---
+
+// Register creates an account. Usernames are 3-32 lower case letters,
+// digits, '.', '_' or '-'.
+func (registry *Registry) Register(username, password string, role Role) (User, error) {
+	if !validUsername.MatchString(username) {
+		return User{}, errors.New("username must be 3-32 lower case letters, digits, '.', '_' or '-'")
+	}
+	if len(password) < MinPasswordLength {
+		return User{}, fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	if role != Coach && role != Athlete && role != Organiser {
+		return User{}, fmt.Errorf("unknown role %q", role)
+	}
+	salt := make([]byte, saltLength)
+	rand.Read(salt)
+	hash, err := pbkdf2.Key(sha256.New, password, salt, hashIterations, hashLength)
+	if err != nil {
+		return User{}, err
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, exists := registry.users[username]; exists {
+		return User{}, ErrUsernameTaken
+	}
+	user := &User{Username: username, Role: role, Coaches: []string{}, Created: time.Now().UTC(), Salt: salt, Hash: hash, Iterations: hashIterations}
+	registry.users[username] = user
+	if err := registry.save(); err != nil {
+		delete(registry.users, username)
+		return User{}, err
+	}
+	return user.copy(), nil
+}
+
+// Authenticate checks a username and password. Unknown users take as long
+// as a wrong password so usernames cannot be probed by timing.
+func (registry *Registry) Authenticate(username, password string) (User, error) {
+	registry.mu.RLock()
+	user, exists := registry.users[username]
+	var stored User
+	if exists {
+		stored = user.copy()
+	}
+	registry.mu.RUnlock()
+	if !exists {
+		stored = User{Salt: make([]byte, saltLength), Hash: make([]byte, hashLength), Iterations: hashIterations}
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, password, stored.Salt, stored.Iterations, len(stored.Hash))
+	if err != nil {
+		return User{}, err
+	}
+	if !exists || subtle.ConstantTimeCompare(hash, stored.Hash) != 1 {
+		return User{}, ErrInvalidCredentials
+	}
+	return stored, nil
+}
+
+func (registry *Registry) User(username string) (User, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	user, exists := registry.users[username]
+	if !exists {
+		return User{}, false
+	}
+	return user.copy(), true
+}
+
+func (user *User) copy() User {
+	c := *user
+	c.Coaches = slices.Clone(user.Coaches)
+	return c
+}
+
+// --- Coach Links ---
+
+// LinkCoach shares an athlete's routines with a coach.
+func (registry *Registry) LinkCoach(athlete, coach string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	athleteUser, exists := registry.users[athlete]
+	if !exists {
+		return ErrUnknownUser
+	}
+	coachUser, exists := registry.users[coach]
+	if !exists || coachUser.Role != Coach {
+		return fmt.Errorf("no coach named %q", coach)
+	}
+	if athleteUser.Role != Athlete {
+		return errors.New("only athletes can join a squad")
+	}
+	if slices.Contains(athleteUser.Coaches, coach) {
+		return nil
+	}
+	athleteUser.Coaches = append(athleteUser.Coaches, coach)
+	return registry.save()
+}
+
+// UnlinkCoach removes the link between an athlete and a coach. Either of
+// them may remove it.
+func (registry *Registry) UnlinkCoach(athlete, coach string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	athleteUser, exists := registry.users[athlete]
+	if !exists || !slices.Contains(athleteUser.Coaches, coach) {
+		return fmt.Errorf("%q is not in %q's squad", athlete, coach)
+	}
+	athleteUser.Coaches = slices.DeleteFunc(athleteUser.Coaches, func(name string) bool { return name == coach })
+	return registry.save()
+}
+
+// Squad returns the usernames of a coach's athletes, sorted.
+func (registry *Registry) Squad(coach string) []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	squad := []string{}
+	for _, user := range registry.users {
+		if slices.Contains(user.Coaches, coach) {
+			squad = append(squad, user.Username)
+		}
+	}
+	sort.Strings(squad)
+	return squad
+}
+
+// CanView reports whether viewer may see owner's routines: their own, or
+// those of an athlete in their squad.
+func (registry *Registry) CanView(viewer, owner string) bool {
+	if viewer == owner {
+		return true
+	}
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	user, exists := registry.users[owner]
+	return exists && slices.Contains(user.Coaches, viewer)
+}
+
+// --- Sessions ---
+
+// NewSession signs a user in and returns the session token for their cookie.
+func (registry *Registry) NewSession(username string) string {
+	token := make([]byte, 32)
+	rand.Read(token)
+	id := base64.RawURLEncoding.EncodeToString(token)
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	now := time.Now()
+	for key, s := range registry.sessions {
+		if now.After(s.expires) {
+			delete(registry.sessions, key)
+		}
+	}
+	registry.sessions[id] = session{username: username, expires: now.Add(SessionLifetime)}
+	return id
+}
+
+// SessionUser returns the user signed in with a session token.
+func (registry *Registry) SessionUser(token string) (User, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	s, exists := registry.sessions[token]
+	if !exists || time.Now().After(s.expires) {
+		return User{}, false
+	}
+	user, exists := registry.users[s.username]
+	if !exists {
+		return User{}, false
+	}
+	return user.copy(), true
+}
+
+func (registry *Registry) EndSession(token string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	delete(registry.sessions, token)
+}
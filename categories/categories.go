@@ -0,0 +1,155 @@
+package categories
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"tariffCalculator/skills"
+)
+
+// Profile adds a competition category's restrictions to the senior routine
+// rules (ten skills, tenth lands on feet, duplicates count once). Zero values
+// mean no restriction. Register additional profiles with RegisterProfile.
+type Profile struct {
+	ID          string        `json:"id"`   // Stable key used in requests, e.g. "junior"
+	Name        string        `json:"name"` // Human readable label, e.g. "Junior"
+	MaxTariff   float64       `json:"maxTariff"`
+	MaxRotation int           `json:"maxRotation"` // Most 1/4 somersaults in a single skill
+	Banned      []SkillRule   `json:"banned"`
+	MinContent  []ContentRule `json:"minContent"`
+}
+
+// SkillRule picks out a kind of skill, e.g. "Triple Somersault".
+type SkillRule struct {
+	Description string                                   `json:"description"`
+	Matches     func(skill *skills.TrampolineSkill) bool `json:"-"`
+}
+
+// ContentRule requires at least Count counted skills matching the rule.
+type ContentRule struct {
+	SkillRule
+	Count int `json:"count"`
+}
+
+// DefaultProfileID is the profile used when none is selected.
+const DefaultProfileID = "senior"
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = map[string]*Profile{}
+)
+
+func init() {
+	RegisterProfile(&Profile{ID: "senior", Name: "Senior"})
+	RegisterProfile(&Profile{
+		ID:     "junior",
+		Name:   "Junior",
+		Banned: []SkillRule{tripleSomersault},
+	})
+	RegisterProfile(&Profile{
+		ID:          "under-15",
+		Name:        "Under 15",
+		MaxTariff:   10,
+		MaxRotation: 9,
+		MinContent:  []ContentRule{{SkillRule: twistingSomersault, Count: 2}},
+	})
+	RegisterProfile(&Profile{
+		ID:          "club-grade",
+		Name:        "Club Grade",
+		MaxTariff:   3,
+		MaxRotation: 5,
+		MinContent: []ContentRule{
+			{SkillRule: somersault, Count: 1},
+			{SkillRule: twist, Count: 2},
+		},
+	})
+}
+
+var (
+	somersault = SkillRule{Description: "Somersault", Matches: func(skill *skills.TrampolineSkill) bool {
+		return skill.Rotation >= 4
+	}}
+	tripleSomersault = SkillRule{Description: "Triple Somersault", Matches: func(skill *skills.TrampolineSkill) bool {
+		return skill.Rotation >= 12
+	}}
+	twist = SkillRule{Description: "Twisting Skill", Matches: func(skill *skills.TrampolineSkill) bool {
+		return skill.TotalTwist() > 0
+	}}
+	twistingSomersault = SkillRule{Description: "Twisting Somersault", Matches: func(skill *skills.TrampolineSkill) bool {
+		return skill.Rotation >= 4 && skill.TotalTwist() > 0
+	}}
+)
+
+// RegisterProfile makes a profile available under its ID, replacing any
+// profile already registered with the same ID.
+func RegisterProfile(profile *Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[profile.ID] = profile
+}
+
+func GetProfile(id string) (*Profile, bool) {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	profile, exists := profiles[id]
+	return profile, exists
+}
+
+func DefaultProfile() *Profile {
+	profile, _ := GetProfile(DefaultProfileID)
+	return profile
+}
+
+// Profiles returns every registered profile, the default first and the rest
+// by name.
+func Profiles() []*Profile {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	list := make([]*Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].ID == DefaultProfileID) != (list[j].ID == DefaultProfileID) {
+			return list[i].ID == DefaultProfileID
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// CheckSkill returns the profile's violations for a single skill.
+func (profile *Profile) CheckSkill(skill *skills.TrampolineSkill) []string {
+	var violations []string
+	if profile.MaxRotation > 0 && skill.Rotation > profile.MaxRotation {
+		violations = append(violations, fmt.Sprintf("Rotation Over Category Limit (%d/4 > %d/4)", skill.Rotation, profile.MaxRotation))
+	}
+	for _, rule := range profile.Banned {
+		if rule.Matches(skill) {
+			violations = append(violations, fmt.Sprintf("%s Not Allowed In %s", rule.Description, profile.Name))
+		}
+	}
+	return violations
+}
+
+// CheckRoutine returns the profile's routine-level violations. counted holds
+// the skills that count towards the tariff and tariff is their total.
+func (profile *Profile) CheckRoutine(counted []skills.TrampolineSkill, tariff float64) []string {
+	var violations []string
+	if profile.MaxTariff > 0 && tariff > profile.MaxTariff {
+		violations = append(violations, fmt.Sprintf("Tariff %.2f Over %s Maximum %.2f", tariff, profile.Name, profile.MaxTariff))
+	}
+	for _, rule := range profile.MinContent {
+		found := 0
+		for i := range counted {
+			if rule.Matches(&counted[i]) {
+				found++
+			}
+		}
+		if found < rule.Count {
+			violations = append(violations, fmt.Sprintf("%s Requires %d x %s (Found %d)", profile.Name, rule.Count, rule.Description, found))
+		}
+	}
+	return violations
+}
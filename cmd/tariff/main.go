@@ -0,0 +1,376 @@
+// cmd/tariff/main.go
+//
+// tariff calculates skill tariffs and checks routines without the web
+// server, so entries can be checked offline:
+//
+//	tariff skill [flags] [NOTATION ...]
+//	tariff routine [flags] [FILE ...]
+//	tariff organiser [flags] USERNAME
+//
+// Routine files hold a JSON array of skills, as saved by the calculator;
+// with no files, or "-", the routine is read from standard input. The exit
+// status is 0 when every skill lands and every routine is valid, 1 when one
+// is not and 2 when an argument or file could not be read.
+//
+// Organiser accounts run competitions and cannot be registered through the
+// web server, so they are added to its accounts file with "tariff organiser"
+// while the server is stopped.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"tariffCalculator/accounts"
+	"tariffCalculator/categories"
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+const (
+	exitValid   = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitError)
+	}
+	var status int
+	switch os.Args[1] {
+	case "skill":
+		status = runSkill(os.Args[2:])
+	case "routine":
+		status = runRoutine(os.Args[2:])
+	case "organiser":
+		status = runOrganiser(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "tariff: unknown command %q\n", os.Args[1])
+		usage()
+		status = exitError
+	}
+	os.Exit(status)
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `Usage:
+  tariff skill [flags] [NOTATION ...]   tariff, landing and name of skills
+  tariff routine [flags] [FILE ...]     validate routines from JSON files or stdin
+  tariff organiser [flags] USERNAME     add an organiser account, password from stdin
+
+Run "tariff skill -h", "tariff routine -h" or "tariff organiser -h" for the flags.
+`)
+}
+
+// --- Shared Flags ---
+
+type commonFlags struct {
+	rules     string
+	catalogue string
+	json      bool
+}
+
+func (f *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.rules, "rules", skills.DefaultTariffRulesID, "tariff rule set: "+ruleSetIDs())
+	fs.StringVar(&f.catalogue, "catalogue", os.Getenv("SKILL_CATALOGUE"), "extra common skill catalogue `file` (JSON), as for the server")
+	fs.BoolVar(&f.json, "json", false, "print JSON instead of text")
+}
+
+// setup loads the catalogue and returns the selected rule set.
+func (f *commonFlags) setup() (skills.TariffRules, error) {
+	if f.catalogue != "" {
+		catalogue, err := skills.LoadCatalogue(f.catalogue)
+		if err != nil {
+			return nil, err
+		}
+		skills.SetCatalogue(catalogue)
+	}
+	rules, ok := skills.GetTariffRules(f.rules)
+	if !ok {
+		return nil, fmt.Errorf("unknown rule set %q, expected one of %s", f.rules, ruleSetIDs())
+	}
+	return rules, nil
+}
+
+func ruleSetIDs() string {
+	var ids []string
+	for _, rules := range skills.TariffRuleSets() {
+		ids = append(ids, rules.ID())
+	}
+	return strings.Join(ids, ", ")
+}
+
+// --- Skills ---
+
+// skillReport is a skill as printed by "tariff skill".
+type skillReport struct {
+	Name            string  `json:"name"`
+	FIGNotation     string  `json:"fig_notation"`
+	Tariff          float64 `json:"tariff"`
+	LandingPosition string  `json:"landing_position"`
+}
+
+func runSkill(args []string) int {
+	fs := flag.NewFlagSet("tariff skill", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	rotation := fs.Int("rotation", 0, "quarter somersaults")
+	twists := fs.String("twists", "", "half twists in each somersault phase, comma separated, e.g. 1,3")
+	shape := fs.String("shape", "straight", "straight, tuck, pike or straddle")
+	takeoff := fs.String("takeoff", "feet", "takeoff position: feet, front, back or seat")
+	backward := fs.Bool("backward", false, "backward rotation")
+	seatLanding := fs.Bool("seat-landing", false, "lands on seat")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: tariff skill [flags] [NOTATION ...]\n\nEach FIG notation, e.g. \"(8 - 1 <)\", is one skill; -takeoff, -backward and\n-seat-landing apply to all of them. Without notation the skill is built from the flags.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	rules, err := common.setup()
+	if err != nil {
+		return fail(err)
+	}
+	takeoffPosition := skills.BodyPositionFromString(*takeoff)
+	if takeoffPosition == skills.Invalid {
+		return fail(fmt.Errorf("unknown takeoff position %q", *takeoff))
+	}
+
+	var skillList []skills.TrampolineSkill
+	if fs.NArg() == 0 {
+		skill := skills.TrampolineSkill{Rotation: *rotation, Shape: shapeFromFlag(*shape), TakeoffPosition: takeoffPosition, Backward: *backward, SeatLanding: *seatLanding}
+		if skill.Shape == skills.InvalidShape {
+			return fail(fmt.Errorf("unknown shape %q", *shape))
+		}
+		if skill.TwistDistribution, err = parseTwists(*twists); err != nil {
+			return fail(err)
+		}
+		if skill.Rotation < 0 || len(skill.TwistDistribution) > skills.CalculatePhases(skill.Rotation) {
+			return fail(fmt.Errorf("rotation %d has %d twist phases", skill.Rotation, skills.CalculatePhases(skill.Rotation)))
+		}
+		skillList = append(skillList, skill)
+	}
+	for _, notation := range fs.Args() {
+		skill, err := skills.ParseFIGNotation(notation)
+		if err != nil {
+			return fail(err)
+		}
+		skill.TakeoffPosition = takeoffPosition
+		skill.Backward = *backward
+		skill.SeatLanding = *seatLanding
+		skillList = append(skillList, skill)
+	}
+
+	status := exitValid
+	reports := make([]skillReport, len(skillList))
+	for i := range skillList {
+		skill := &skillList[i]
+		routine.NormalizeTwists(skill)
+		skill.Name = routine.SkillName(*skill)
+		skill.SetTariff(rules)
+		reports[i] = skillReport{Name: skill.Name, FIGNotation: skill.FIGNotation(), Tariff: skill.Tariff, LandingPosition: skill.LandingPosition().String()}
+		if skill.LandingPosition() == skills.Invalid {
+			status = exitInvalid
+		}
+	}
+
+	if common.json {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, report := range reports {
+			encoder.Encode(report)
+		}
+		return status
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tFIG\tTARIFF\tLANDING")
+	for _, report := range reports {
+		fmt.Fprintf(tw, "%s\t%s\t%.1f\t%s\n", report.Name, report.FIGNotation, report.Tariff, report.LandingPosition)
+	}
+	tw.Flush()
+	return status
+}
+
+func shapeFromFlag(name string) skills.Shape {
+	for shape, shapeName := range skills.ShapeName {
+		if strings.EqualFold(name, shapeName) {
+			return shape
+		}
+	}
+	return skills.InvalidShape
+}
+
+func parseTwists(value string) ([]int, error) {
+	var twists []int
+	if strings.TrimSpace(value) == "" {
+		return twists, nil
+	}
+	for _, field := range strings.Split(value, ",") {
+		twist, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || twist < 0 {
+			return nil, fmt.Errorf("invalid twist %q in -twists", field)
+		}
+		twists = append(twists, twist)
+	}
+	return twists, nil
+}
+
+// --- Routines ---
+
+// routineReport is one routine as printed by "tariff routine -json", one
+// per line.
+type routineReport struct {
+	File       string         `json:"file"`
+	Valid      bool           `json:"valid"`
+	Validation routine.Result `json:"validation"`
+}
+
+func runRoutine(args []string) int {
+	fs := flag.NewFlagSet("tariff routine", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	profileID := fs.String("profile", categories.DefaultProfileID, "category profile: "+profileIDs())
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: tariff routine [flags] [FILE ...]\n\nEach FILE holds a JSON array of skills; \"-\" or no FILE reads standard input.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	rules, err := common.setup()
+	if err != nil {
+		return fail(err)
+	}
+	profile, ok := categories.GetProfile(*profileID)
+	if !ok {
+		return fail(fmt.Errorf("unknown profile %q, expected one of %s", *profileID, profileIDs()))
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := exitValid
+	encoder := json.NewEncoder(os.Stdout)
+	for _, file := range files {
+		skillList, err := readRoutine(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "tariff: %s: %v\n", file, err)
+			status = exitError
+			continue
+		}
+		skillList.Prepare()
+		result := skillList.Validate(routine.Options{Rules: rules, Profile: profile})
+		if !result.Valid() && status == exitValid {
+			status = exitInvalid
+		}
+		if common.json {
+			encoder.Encode(routineReport{File: file, Valid: result.Valid(), Validation: result})
+		} else {
+			printRoutineReport(os.Stdout, file, result, rules, profile)
+		}
+	}
+	return status
+}
+
+func readRoutine(file string) (routine.Routine, error) {
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	var skillList routine.Routine
+	decoder := json.NewDecoder(in)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&skillList); err != nil {
+		return nil, err
+	}
+	if len(skillList) == 0 {
+		return nil, errors.New("empty routine")
+	}
+	for i, skill := range skillList {
+		if skill.Shape == skills.InvalidShape || skill.TakeoffPosition == skills.Invalid || skill.Rotation < 0 {
+			return nil, fmt.Errorf("skill %d: invalid shape, takeoff position or rotation", i+1)
+		}
+	}
+	return skillList, nil
+}
+
+// printRoutineReport prints a routine's skills with their problems, then the
+// routine level problems.
+func printRoutineReport(w io.Writer, file string, result routine.Result, rules skills.TariffRules, profile *categories.Profile) {
+	verdict := "VALID"
+	if !result.Valid() {
+		verdict = "INVALID"
+	}
+	fmt.Fprintf(w, "%s: %s, tariff %.1f (%s, %s)\n", file, verdict, result.TotalTariff, rules.Name(), profile.Name)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for i, skill := range result.Skills {
+		fmt.Fprintf(tw, "  %d.\t%s\t%s\t%.1f\t%s\t%s\n", i+1, skill.Name, skill.FIGNotation, skill.Tariff, skill.LandingPosStr, result.Messages[i])
+	}
+	tw.Flush()
+	for _, violation := range result.ProfileViolations {
+		fmt.Fprintf(w, "  %s\n", violation)
+	}
+	fmt.Fprintln(w)
+}
+
+func profileIDs() string {
+	var ids []string
+	for _, profile := range categories.Profiles() {
+		ids = append(ids, profile.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// --- Accounts ---
+
+func runOrganiser(args []string) int {
+	fs := flag.NewFlagSet("tariff organiser", flag.ExitOnError)
+	file := fs.String("accounts", os.Getenv("ACCOUNTS_FILE"), "accounts `file` of the server, as ACCOUNTS_FILE")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: tariff organiser [flags] USERNAME\n\nAdds an organiser account to the server's accounts file. The password is\nthe first line of standard input. Stop the server first: it rewrites the\nfile from memory and only reads it when starting.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+	if *file == "" {
+		return fail(errors.New("no accounts file, set -accounts or ACCOUNTS_FILE"))
+	}
+
+	registry, err := accounts.OpenRegistry(*file)
+	if err != nil {
+		return fail(err)
+	}
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fail(err)
+	}
+	user, err := registry.Register(fs.Arg(0), strings.TrimRight(password, "\r\n"), accounts.Organiser)
+	if err != nil {
+		return fail(err)
+	}
+	fmt.Printf("Added organiser %s to %s\n", user.Username, *file)
+	return exitValid
+}
+
+func fail(err error) int {
+	fmt.Fprintf(os.Stderr, "tariff: %v\n", err)
+	return exitError
+}
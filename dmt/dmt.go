@@ -0,0 +1,155 @@
+package dmt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"tariffCalculator/skills"
+)
+
+// PassKind says where the first skill of a pass is performed.
+type PassKind int
+
+const (
+	MounterPass PassKind = iota // First skill from the mount onto the spot bed
+	SpotterPass                 // Straight jump onto the spot bed, first skill performed from it
+)
+
+var PassKindName = map[PassKind]string{
+	MounterPass: "Mounter",
+	SpotterPass: "Spotter",
+}
+
+func (kind PassKind) String() string {
+	return PassKindName[kind]
+}
+
+func (kind PassKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(kind.String())
+}
+func (kind *PassKind) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	for k, v := range PassKindName {
+		if strings.EqualFold(s, v) {
+			*kind = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown pass kind %q", s)
+}
+
+// Pass is one double mini-trampoline pass: a mounter or spotter skill followed
+// by the dismount onto the landing mat.
+type Pass struct {
+	Kind     PassKind               `json:"kind"`
+	First    skills.TrampolineSkill `json:"first"`
+	Dismount skills.TrampolineSkill `json:"dismount"`
+}
+
+// FirstRole is the label of the pass's first skill, "Mounter" or "Spotter".
+func (pass *Pass) FirstRole() string {
+	return pass.Kind.String()
+}
+
+type ValidatedElement struct {
+	skills.TrampolineSkill
+	Role          string                 `json:"role"`
+	LandingPosStr string                 `json:"landing_position"`
+	FIGNotation   string                 `json:"FIGNotation"`
+	Breakdown     skills.TariffBreakdown `json:"breakdown"`
+	Counts        bool                   `json:"counts"`
+	Messages      []string               `json:"messages"`
+}
+
+type PassValidationData struct {
+	Kind        PassKind           `json:"kind"`
+	Elements    []ValidatedElement `json:"elements"`
+	TotalTariff float64            `json:"totalTariff"`
+	Valid       bool               `json:"valid"`
+	Messages    []string           `json:"messages"`
+}
+
+// Validate checks the pass against DMT's pass-level rules and totals its
+// tariff with Rules. Both skills must take off from and land on feet. A
+// straight jump is a valid mounter or spotter worth nothing, but the dismount
+// must have rotation or twist to count as an element.
+func (pass *Pass) Validate() PassValidationData {
+	data := PassValidationData{Kind: pass.Kind, Valid: true, Messages: []string{}}
+
+	for i, skill := range []skills.TrampolineSkill{pass.First, pass.Dismount} {
+		element := ValidatedElement{TrampolineSkill: skill, Role: pass.FirstRole(), Counts: true, Messages: []string{}}
+		if i == 1 {
+			element.Role = "Dismount"
+		}
+		element.SetTariff(Rules)
+		element.Breakdown = element.TariffBreakdown(Rules)
+		element.FIGNotation = element.TrampolineSkill.FIGNotation()
+		landing := element.LandingPosition()
+		element.LandingPosStr = landing.String()
+
+		if err := element.TrampolineSkill.Validate(); err != nil {
+			element.Messages = append(element.Messages, "Twist phases: "+err.Error())
+		}
+		if element.TakeoffPosition != skills.Feet {
+			element.Messages = append(element.Messages, fmt.Sprintf("Must Take Off From Feet (got %s)", element.TakeoffPosition))
+		}
+		if element.SeatLanding || landing != skills.Feet {
+			element.Messages = append(element.Messages, fmt.Sprintf("Must Land On Feet (got %s)", landing))
+		}
+		if element.Rotation == 0 && element.TotalTwist() == 0 {
+			element.Counts = false
+			if i == 1 {
+				element.Messages = append(element.Messages, "No Rotation Or Twist (Not An Element)")
+			}
+		}
+
+		if len(element.Messages) > 0 {
+			data.Valid = false
+			data.Messages = append(data.Messages, fmt.Sprintf("%s: %s", element.Role, strings.Join(element.Messages, " / ")))
+		}
+		if element.Counts {
+			data.TotalTariff += element.Tariff
+		}
+		data.Elements = append(data.Elements, element)
+	}
+	return data
+}
+
+// somersaultTable is indexed by the number of completed somersaults. Values
+// per quarter somersault and half twist grow with the number of somersaults,
+// so a double back tuck is 2.0 and a triple back tuck 4.4.
+//
+// These values have not yet been checked against the difficulty tables of
+// the FIG Double Mini-Trampoline Code of Points; check them before relying
+// on DMT tariffs.
+var somersaultTable = skills.SomersaultTable{
+	{Label: "Rotation", PerQuarter: 1, PerHalfTwist: 1},
+	{Label: "Single somersault", PerQuarter: 1, PerHalfTwist: 1, Completion: 1, StraightPike: 1, UntwistedOnly: true},
+	{Label: "Double somersault", PerQuarter: 2, PerHalfTwist: 2, Completion: 4, StraightPike: 2},
+	{Label: "Triple somersault", PerQuarter: 3, PerHalfTwist: 3, Completion: 8, StraightPike: 4},
+	{Label: "Quadruple somersault", PerQuarter: 4, PerHalfTwist: 4, Completion: 12, StraightPike: 6},
+}
+
+type dmtRules struct{}
+
+// Rules is the DMT code of points. It satisfies skills.TariffRules so DMT
+// tariffs are calculated with the same SetTariff and TariffBreakdown calls as
+// trampoline, but it is not registered with the trampoline rule sets.
+var Rules skills.TariffRules = dmtRules{}
+
+func (dmtRules) ID() string   { return "fig-dmt" }
+func (dmtRules) Name() string { return "FIG Double Mini-Trampoline" }
+
+func (rules dmtRules) Tariff(skill *skills.TrampolineSkill) float64 {
+	return rules.Breakdown(skill).Total
+}
+
+func (dmtRules) Breakdown(skill *skills.TrampolineSkill) skills.TariffBreakdown {
+	var b skills.BreakdownBuilder
+	somersaultTable.Add(&b, skill)
+	return b.Result()
+}
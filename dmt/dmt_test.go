@@ -0,0 +1,59 @@
+package dmt
+
+import (
+	"encoding/json"
+	"testing"
+
+	"tariffCalculator/skills"
+)
+
+func TestValidate(t *testing.T) {
+	straightJump := skills.TrampolineSkill{Shape: skills.Straight, TwistDistribution: []int{0}}
+	backTuck := skills.TrampolineSkill{Rotation: 4, Backward: true, Shape: skills.Tuck, TwistDistribution: []int{0}}
+	doubleBack := skills.TrampolineSkill{Rotation: 8, Backward: true, Shape: skills.Tuck, TwistDistribution: []int{0, 0}}
+	tests := []struct {
+		name   string
+		pass   Pass
+		valid  bool
+		counts []bool
+	}{
+		{"mounter and dismount", Pass{Kind: MounterPass, First: backTuck, Dismount: doubleBack}, true, []bool{true, true}},
+		{"straight jump mounter", Pass{Kind: MounterPass, First: straightJump, Dismount: doubleBack}, true, []bool{false, true}},
+		{"straight jump spotter", Pass{Kind: SpotterPass, First: straightJump, Dismount: doubleBack}, true, []bool{false, true}},
+		{"straight jump dismount", Pass{Kind: SpotterPass, First: backTuck, Dismount: straightJump}, false, []bool{true, false}},
+	}
+	for _, test := range tests {
+		data := test.pass.Validate()
+		if data.Valid != test.valid {
+			t.Errorf("%s: valid %v, want %v (%v)", test.name, data.Valid, test.valid, data.Messages)
+		}
+		if data.Kind != test.pass.Kind || data.Elements[0].Role != test.pass.Kind.String() {
+			t.Errorf("%s: kind %s and first role %q, want %s", test.name, data.Kind, data.Elements[0].Role, test.pass.Kind)
+		}
+		total := 0.0
+		for i, element := range data.Elements {
+			if element.Counts != test.counts[i] {
+				t.Errorf("%s: %s counts %v, want %v", test.name, element.Role, element.Counts, test.counts[i])
+			}
+			if element.Counts {
+				total += element.Tariff
+			}
+		}
+		if data.TotalTariff != total {
+			t.Errorf("%s: total tariff %.1f, want %.1f", test.name, data.TotalTariff, total)
+		}
+	}
+}
+
+func TestPassKindJSON(t *testing.T) {
+	var pass Pass
+	if err := json.Unmarshal([]byte(`{"kind": "spotter"}`), &pass); err != nil || pass.Kind != SpotterPass {
+		t.Errorf("kind \"spotter\" decoded as %s, error %v", pass.Kind, err)
+	}
+	if err := json.Unmarshal([]byte(`{"kind": "bouncer"}`), &pass); err == nil {
+		t.Error("unknown kind decoded without an error")
+	}
+	if b, _ := json.Marshal(PassValidationData{Kind: MounterPass}); string(b[:17]) != `{"kind":"Mounter"` {
+		t.Errorf("marshalled as %s", b)
+	}
+}
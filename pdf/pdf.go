@@ -0,0 +1,237 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Page sizes in points (1/72 inch).
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font is one of the standard PDF fonts, which every viewer has built in so
+// nothing needs embedding.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+var fontName = map[Font]string{
+	Helvetica:     "Helvetica",
+	HelveticaBold: "Helvetica-Bold",
+}
+
+// Document is a PDF under construction. Pages are drawn on with coordinates
+// in points from the top-left corner, y growing down the page.
+type Document struct {
+	Title  string
+	width  float64
+	height float64
+	pages  []*Page
+}
+
+type Page struct {
+	height  float64
+	content bytes.Buffer
+}
+
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+func (doc *Document) AddPage() *Page {
+	page := &Page{height: doc.height}
+	doc.pages = append(doc.pages, page)
+	return page
+}
+
+// --- Drawing ---
+
+// Text draws text with its baseline starting at (x, y).
+func (page *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&page.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(page.height-y), escape(text))
+}
+
+// TextRight draws text ending at x.
+func (page *Page) TextRight(x, y float64, font Font, size float64, text string) {
+	page.Text(x-TextWidth(font, size, text), y, font, size, text)
+}
+
+// TextCentre draws text centred on x.
+func (page *Page) TextCentre(x, y float64, font Font, size float64, text string) {
+	page.Text(x-TextWidth(font, size, text)/2, y, font, size, text)
+}
+
+func (page *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&page.content, "%s %s m %s %s l S\n", num(x1), num(page.height-y1), num(x2), num(page.height-y2))
+}
+
+// Rect outlines a rectangle whose top-left corner is (x, y).
+func (page *Page) Rect(x, y, w, h float64) {
+	fmt.Fprintf(&page.content, "%s %s %s %s re S\n", num(x), num(page.height-y-h), num(w), num(h))
+}
+
+// FillRect fills a rectangle with a grey level from 0 (black) to 1 (white).
+func (page *Page) FillRect(x, y, w, h, grey float64) {
+	fmt.Fprintf(&page.content, "q %s g %s %s %s %s re f Q\n", num(grey), num(x), num(page.height-y-h), num(w), num(h))
+}
+
+// SetLineWidth sets the width of lines and outlines drawn afterwards.
+func (page *Page) SetLineWidth(width float64) {
+	fmt.Fprintf(&page.content, "%s w\n", num(width))
+}
+
+// SetTextGrey sets the grey level of text drawn afterwards.
+func (page *Page) SetTextGrey(grey float64) {
+	fmt.Fprintf(&page.content, "%s g\n", num(grey))
+}
+
+// --- Output ---
+
+// WriteTo writes the document as a PDF 1.4 file. Objects 1-4 are the
+// catalog, page tree and fonts, followed by each page and its content stream.
+func (doc *Document) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(doc.pages))
+	for i := range doc.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>", strings.Join(kids, " "), len(doc.pages), num(doc.width), num(doc.height)))
+	object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", fontName[Helvetica]))
+	object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", fontName[HelveticaBold]))
+	for i, page := range doc.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F%d 3 0 R /F%d 4 0 R >> >> /Contents %d 0 R >>", Helvetica, HelveticaBold, 6+2*i))
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.content.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (tariff-calculator) >>", escape(doc.Title)))
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, len(offsets), xref)
+	return out.WriteTo(w)
+}
+
+// --- Text Encoding ---
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding has.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// encode converts text to WinAnsiEncoding, replacing characters it lacks
+// with '?'.
+func encode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0x20 && r <= 0x7e, r >= 0xa0 && r <= 0xff:
+			encoded = append(encoded, byte(r))
+		case winAnsi[r] != 0:
+			encoded = append(encoded, winAnsi[r])
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// escape encodes text for a PDF literal string.
+func escape(text string) string {
+	var b strings.Builder
+	for _, c := range encode(text) {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c > 0x7e:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// num formats a number to the hundredth of a point, which is finer than any
+// printer resolves.
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// --- Font Metrics ---
+
+// Advance widths in 1/1000 em of the printable ASCII characters from ' ' to
+// '~', from the standard Adobe font metrics.
+var asciiWidths = map[Font][95]int{
+	Helvetica: {
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+	HelveticaBold: {
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// TextWidth returns the width of text in points. Characters outside ASCII
+// are taken to be as wide as a digit.
+func TextWidth(font Font, size float64, text string) float64 {
+	widths := asciiWidths[font]
+	total := 0
+	for _, c := range encode(text) {
+		if c >= 0x20 && c <= 0x7e {
+			total += widths[c-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Truncate shortens text with an ellipsis to fit within width points.
+func Truncate(font Font, size float64, text string, width float64) string {
+	if TextWidth(font, size, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && TextWidth(font, size, string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// sampleDocument uses every drawing call, on two pages of a small page size.
+func sampleDocument() *Document {
+	doc := New(200, 100)
+	doc.Title = "Card (Test) – Ünïcode ☃"
+	page := doc.AddPage()
+	page.SetLineWidth(0.5)
+	page.Rect(10, 10, 180, 80)
+	page.FillRect(10, 10, 180, 20, 0.9)
+	page.Line(10, 30, 190, 30)
+	page.Text(12, 25, HelveticaBold, 12, "Name (Club)")
+	page.TextRight(188, 25, Helvetica, 10, "12.345")
+	page.SetTextGrey(0.5)
+	page.TextCentre(100, 50, Helvetica, 8, "Back\\slash – 1/3 €")
+	page = doc.AddPage()
+	page.Text(10.004, 20.005, Helvetica, 9, Truncate(Helvetica, 9, "A name far too long for the box", 60))
+	return doc
+}
+
+func TestWriteToGolden(t *testing.T) {
+	var out bytes.Buffer
+	n, err := sampleDocument().WriteTo(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(out.Len()) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, out.Len())
+	}
+
+	golden := filepath.Join("testdata", "sample.pdf")
+	if *update {
+		if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("WriteTo output differs from %s (run go test ./pdf -update after checking the change)", golden)
+	}
+}
+
+// TestWriteToXref checks every cross-reference entry points at its object,
+// so a regenerated golden file is still a readable PDF.
+func TestWriteToXref(t *testing.T) {
+	var out bytes.Buffer
+	sampleDocument().WriteTo(&out)
+	data := out.Bytes()
+
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if match == nil {
+		t.Fatal("no startxref at the end of the file")
+	}
+	xref, _ := strconv.Atoi(string(match[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n0 10\n")) {
+		t.Fatalf("startxref %d does not point at an xref table of 10 entries", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != 9 {
+		t.Fatalf("%d objects in the xref table, want 9", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if header := strconv.Itoa(i+1) + " 0 obj\n"; !bytes.HasPrefix(data[offset:], []byte(header)) {
+			t.Errorf("object %d: offset %d does not point at %q", i+1, offset, header)
+		}
+	}
+}
+
+func TestPageContent(t *testing.T) {
+	var out bytes.Buffer
+	sampleDocument().WriteTo(&out)
+	streams := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(out.Bytes(), -1)
+	if len(streams) != 2 {
+		t.Fatalf("%d content streams, want 2", len(streams))
+	}
+	want := []string{
+		"0.5 w\n" +
+			"10 10 180 80 re S\n" +
+			"q 0.9 g 10 70 180 20 re f Q\n" +
+			"10 70 m 190 70 l S\n" +
+			"BT /F1 12 Tf 12 75 Td (Name \\(Club\\)) Tj ET\n" +
+			"BT /F0 10 Tf 157.42 75 Td (12.345) Tj ET\n" +
+			"0.5 g\n" +
+			"BT /F0 8 Tf 67.32 50 Td (Back\\\\slash \\226 1/3 \\200) Tj ET\n",
+		"BT /F0 9 Tf 10 80 Td (A name far to\\205) Tj ET\n",
+	}
+	for i, stream := range streams {
+		reader, err := zlib.NewReader(bytes.NewReader(stream[1]))
+		if err != nil {
+			t.Fatalf("page %d: %v", i+1, err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("page %d: %v", i+1, err)
+		}
+		if string(content) != want[i] {
+			t.Errorf("page %d content:\n%s\nwant:\n%s", i+1, content, want[i])
+		}
+	}
+}
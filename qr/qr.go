@@ -0,0 +1,491 @@
+package qr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Level is the error correction level: the share of the symbol that can be
+// damaged and still scan.
+type Level int
+
+const (
+	L Level = iota // about 7%
+	M              // about 15%
+	Q              // about 25%
+	H              // about 30%
+)
+
+const (
+	minVersion = 1
+	maxVersion = 40
+
+	byteModeIndicator = 0x4
+	padByteA          = 0xec
+	padByteB          = 0x11
+)
+
+// ErrTooLong is returned when the data does not fit a version 40 symbol.
+var ErrTooLong = errors.New("qr: data too long")
+
+// formatLevelBits are the error correction bits of the format information,
+// which do not follow Level order.
+var formatLevelBits = [4]int{L: 1, M: 0, Q: 3, H: 2}
+
+type blockGroup struct {
+	count, total, data int
+}
+
+// Code is an encoded QR symbol of Size x Size modules, without the quiet
+// zone.
+type Code struct {
+	Version int
+	Level   Level
+	Mask    int
+	Size    int
+	modules []bool
+}
+
+// Dark reports whether the module at column x, row y is dark. Positions
+// outside the symbol are light.
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+		return false
+	}
+	return c.modules[y*c.Size+x]
+}
+
+// Encode encodes data in byte mode in the smallest version that holds it at
+// the given level, choosing the mask with the lowest penalty.
+func Encode(data []byte, level Level) (*Code, error) {
+	if level < L || level > H {
+		return nil, fmt.Errorf("qr: invalid level %d", level)
+	}
+	version := minVersion
+	for ; version <= maxVersion; version++ {
+		if 4+lengthBits(version)+8*len(data) <= 8*dataCodewords(version, level) {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(encodeData(data, version, level), version, level)
+	base := newMatrix(version)
+	var best *Code
+	bestPenalty := 0
+	for mask := 0; mask < 8; mask++ {
+		m := base.clone()
+		m.placeData(codewords, mask)
+		m.placeFormat(level, mask)
+		if penalty := m.penalty(); best == nil || penalty < bestPenalty {
+			best = &Code{Version: version, Level: level, Mask: mask, Size: m.size, modules: m.dark}
+			bestPenalty = penalty
+		}
+	}
+	return best, nil
+}
+
+func lengthBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func dataCodewords(version int, level Level) int {
+	n := 0
+	for _, group := range blockGroups[version-1][level] {
+		n += group.count * group.data
+	}
+	return n
+}
+
+// --- Data Codewords ---
+
+type bitBuffer struct {
+	bytes []byte
+	n     int
+}
+
+func (b *bitBuffer) put(value, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if b.n%8 == 0 {
+			b.bytes = append(b.bytes, 0)
+		}
+		if value>>i&1 == 1 {
+			b.bytes[b.n/8] |= 0x80 >> (b.n % 8)
+		}
+		b.n++
+	}
+}
+
+// encodeData returns the data codewords: a single byte mode segment, the
+// terminator and padding.
+func encodeData(data []byte, version int, level Level) []byte {
+	capacity := dataCodewords(version, level)
+	var buf bitBuffer
+	buf.put(byteModeIndicator, 4)
+	buf.put(len(data), lengthBits(version))
+	for _, b := range data {
+		buf.put(int(b), 8)
+	}
+	buf.put(0, min(4, capacity*8-buf.n))
+	for i := 0; len(buf.bytes) < capacity; i++ {
+		if i%2 == 0 {
+			buf.bytes = append(buf.bytes, padByteA)
+		} else {
+			buf.bytes = append(buf.bytes, padByteB)
+		}
+	}
+	return buf.bytes
+}
+
+// addErrorCorrection splits the data codewords into blocks, appends each
+// block's Reed-Solomon codewords and interleaves the result.
+func addErrorCorrection(data []byte, version int, level Level) []byte {
+	var dataBlocks, ecBlocks [][]byte
+	maxData, offset := 0, 0
+	for _, group := range blockGroups[version-1][level] {
+		for range group.count {
+			block := data[offset : offset+group.data]
+			offset += group.data
+			dataBlocks = append(dataBlocks, block)
+			ecBlocks = append(ecBlocks, reedSolomon(block, group.total-group.data))
+			maxData = max(maxData, group.data)
+		}
+	}
+
+	var out []byte
+	for i := range maxData {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := range ecBlocks[0] {
+		for _, block := range ecBlocks {
+			out = append(out, block[i])
+		}
+	}
+	return out
+}
+
+// --- Reed-Solomon over GF(256) ---
+
+var gfExp, gfLog [256]int
+
+func init() {
+	x := 1
+	for i := range 255 {
+		gfExp[i] = x
+		gfLog[x] = i
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	gfExp[255] = gfExp[0]
+}
+
+func gfMul(a, b int) int {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[(gfLog[a]+gfLog[b])%255]
+}
+
+// reedSolomon returns the n error correction codewords of a block: the
+// remainder of dividing it by the generator polynomial of degree n.
+func reedSolomon(block []byte, n int) []byte {
+	generator := []int{1}
+	for i := range n {
+		next := make([]int, len(generator)+1)
+		for j, coefficient := range generator {
+			next[j] ^= coefficient
+			next[j+1] ^= gfMul(coefficient, gfExp[i])
+		}
+		generator = next
+	}
+
+	remainder := make([]int, n)
+	for _, b := range block {
+		factor := int(b) ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[n-1] = 0
+		for j := range remainder {
+			remainder[j] ^= gfMul(generator[j+1], factor)
+		}
+	}
+	ec := make([]byte, n)
+	for i, v := range remainder {
+		ec[i] = byte(v)
+	}
+	return ec
+}
+
+// --- Matrix ---
+
+type matrix struct {
+	size     int
+	dark     []bool
+	function []bool // Finder, timing, alignment, format and version modules
+}
+
+// newMatrix returns a version's function patterns with the format and
+// version areas reserved.
+func newMatrix(version int) *matrix {
+	size := version*4 + 17
+	m := &matrix{size: size, dark: make([]bool, size*size), function: make([]bool, size*size)}
+
+	m.finder(0, 0)
+	m.finder(size-7, 0)
+	m.finder(0, size-7)
+
+	centres := alignmentCentres[version-1]
+	for _, row := range centres {
+		for _, col := range centres {
+			if !m.function[row*size+col] {
+				m.alignment(row, col)
+			}
+		}
+	}
+
+	// Reserve the format areas; the timing patterns cross them
+	for i := 0; i <= 8; i++ {
+		m.set(8, i, false)
+		m.set(i, 8, false)
+		if i < 8 {
+			m.set(8, size-1-i, false)
+			m.set(size-1-i, 8, false)
+		}
+	}
+
+	for i := 8; i < size-8; i++ {
+		m.set(6, i, i%2 == 0)
+		m.set(i, 6, i%2 == 0)
+	}
+
+	if version >= 7 {
+		bits := versionBits(version)
+		for i := range 18 {
+			dark := bits>>i&1 == 1
+			m.set(i/3, size-11+i%3, dark)
+			m.set(size-11+i%3, i/3, dark)
+		}
+	}
+	return m
+}
+
+func (m *matrix) set(row, col int, dark bool) {
+	m.dark[row*m.size+col] = dark
+	m.function[row*m.size+col] = true
+}
+
+func (m *matrix) clone() *matrix {
+	return &matrix{size: m.size, dark: append([]bool(nil), m.dark...), function: m.function}
+}
+
+// finder draws a finder pattern with its top-left corner at row, col, and
+// the light separator around it.
+func (m *matrix) finder(row, col int) {
+	for r := -1; r <= 7; r++ {
+		for c := -1; c <= 7; c++ {
+			if row+r < 0 || row+r >= m.size || col+c < 0 || col+c >= m.size {
+				continue
+			}
+			ring := max(abs(r-3), abs(c-3))
+			m.set(row+r, col+c, ring != 2 && ring != 4)
+		}
+	}
+}
+
+func (m *matrix) alignment(row, col int) {
+	for r := -2; r <= 2; r++ {
+		for c := -2; c <= 2; c++ {
+			m.set(row+r, col+c, max(abs(r), abs(c)) != 1)
+		}
+	}
+}
+
+// placeData fills the non-function modules with the codewords in the
+// standard two-column zigzag from the bottom right, applying the mask.
+func (m *matrix) placeData(codewords []byte, mask int) {
+	bit := 0
+	upward := true
+	for right := m.size - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right-- // Skip the vertical timing pattern
+		}
+		for i := range m.size {
+			row := i
+			if upward {
+				row = m.size - 1 - i
+			}
+			for col := right; col > right-2; col-- {
+				if m.function[row*m.size+col] {
+					continue
+				}
+				dark := false
+				if bit/8 < len(codewords) {
+					dark = codewords[bit/8]>>(7-bit%8)&1 == 1
+				}
+				bit++
+				m.dark[row*m.size+col] = dark != masked(mask, row, col)
+			}
+		}
+		upward = !upward
+	}
+}
+
+func masked(mask, row, col int) bool {
+	switch mask {
+	case 0:
+		return (row+col)%2 == 0
+	case 1:
+		return row%2 == 0
+	case 2:
+		return col%3 == 0
+	case 3:
+		return (row+col)%3 == 0
+	case 4:
+		return (row/2+col/3)%2 == 0
+	case 5:
+		return row*col%2+row*col%3 == 0
+	case 6:
+		return (row*col%2+row*col%3)%2 == 0
+	default:
+		return (row*col%3+(row+col)%2)%2 == 0
+	}
+}
+
+// placeFormat writes both copies of the format information and the dark
+// module.
+func (m *matrix) placeFormat(level Level, mask int) {
+	bits := formatBits(level, mask)
+	for i := range 15 {
+		dark := bits>>i&1 == 1
+		switch {
+		case i < 6:
+			m.dark[i*m.size+8] = dark
+		case i < 8:
+			m.dark[(i+1)*m.size+8] = dark
+		default:
+			m.dark[(m.size-15+i)*m.size+8] = dark
+		}
+		switch {
+		case i < 8:
+			m.dark[8*m.size+m.size-1-i] = dark
+		case i == 8:
+			m.dark[8*m.size+7] = dark
+		default:
+			m.dark[8*m.size+14-i] = dark
+		}
+	}
+	m.dark[(m.size-8)*m.size+8] = true
+}
+
+// formatBits returns the 15-bit BCH coded format information.
+func formatBits(level Level, mask int) int {
+	data := formatLevelBits[level]<<3 | mask
+	return (data<<10 | bchRemainder(data<<10, 0x537)) ^ 0x5412
+}
+
+// versionBits returns the 18-bit BCH coded version information.
+func versionBits(version int) int {
+	return version<<12 | bchRemainder(version<<12, 0x1f25)
+}
+
+func bchRemainder(value, generator int) int {
+	for bitLength(value) >= bitLength(generator) {
+		value ^= generator << (bitLength(value) - bitLength(generator))
+	}
+	return value
+}
+
+func bitLength(value int) int {
+	n := 0
+	for ; value != 0; value >>= 1 {
+		n++
+	}
+	return n
+}
+
+// --- Mask Penalty ---
+
+// penalty scores a masked symbol by the four rules of ISO/IEC 18004: long
+// runs, 2x2 blocks, finder-like patterns and dark/light imbalance.
+func (m *matrix) penalty() int {
+	n := m.size
+	at := func(row, col int, transpose bool) bool {
+		if transpose {
+			return m.dark[col*n+row]
+		}
+		return m.dark[row*n+col]
+	}
+
+	penalty := 0
+	for _, transpose := range []bool{false, true} {
+		for row := range n {
+			run := 0
+			for col := range n {
+				if col > 0 && at(row, col, transpose) == at(row, col-1, transpose) {
+					run++
+				} else {
+					run = 1
+				}
+				if run == 5 {
+					penalty += 3
+				} else if run > 5 {
+					penalty++
+				}
+			}
+			for col := 0; col+7 <= n; col++ {
+				if !finderLike(row, col, transpose, at) {
+					continue
+				}
+				before := col >= 4 && !at(row, col-1, transpose) && !at(row, col-2, transpose) && !at(row, col-3, transpose) && !at(row, col-4, transpose)
+				after := col+11 <= n && !at(row, col+7, transpose) && !at(row, col+8, transpose) && !at(row, col+9, transpose) && !at(row, col+10, transpose)
+				if before || after {
+					penalty += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for row := range n {
+		for col := range n {
+			if m.dark[row*n+col] {
+				dark++
+			}
+			if row+1 < n && col+1 < n {
+				d := m.dark[row*n+col]
+				if d == m.dark[row*n+col+1] && d == m.dark[(row+1)*n+col] && d == m.dark[(row+1)*n+col+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	penalty += abs(dark*20-n*n*10) / (n * n) * 10
+	return penalty
+}
+
+// finderLike reports a dark-light-dark-dark-dark-light-dark run at col.
+func finderLike(row, col int, transpose bool, at func(int, int, bool) bool) bool {
+	for i, dark := range [7]bool{true, false, true, true, true, false, true} {
+		if at(row, col+i, transpose) != dark {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,211 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		ec   []byte
+	}{
+		{
+			// ISO/IEC 18004 Annex I: "01234567" as version 1-M
+			"01234567",
+			[]byte{0x10, 0x20, 0x0c, 0x56, 0x61, 0x80, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11},
+			[]byte{0xa5, 0x24, 0xd4, 0xc1, 0xed, 0x36, 0xc7, 0x87, 0x2c, 0x55},
+		},
+		{
+			// "HELLO WORLD" in alphanumeric mode as version 1-M
+			"HELLO WORLD",
+			[]byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17},
+			[]byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23},
+		},
+	}
+	for _, test := range tests {
+		if got := reedSolomon(test.data, len(test.ec)); !bytes.Equal(got, test.ec) {
+			t.Errorf("%s: reedSolomon = % x, want % x", test.name, got, test.ec)
+		}
+	}
+}
+
+func TestEncodeData(t *testing.T) {
+	// Mode 0100, length 00000001, 'A' 01000001, terminator 0000, then pad
+	// bytes to the 16 data codewords of version 1-M
+	want := []byte{0x40, 0x14, 0x10, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec, 0x11, 0xec}
+	if got := encodeData([]byte("A"), 1, M); !bytes.Equal(got, want) {
+		t.Errorf("encodeData(\"A\", 1-M) = % x, want % x", got, want)
+	}
+}
+
+func TestFormatBits(t *testing.T) {
+	tests := []struct {
+		level Level
+		mask  int
+		want  int
+	}{
+		{L, 0, 0b111011111000100},
+		{L, 4, 0b110011000101111},
+		{L, 7, 0b110100101110110},
+		{M, 0, 0b101010000010010},
+		{M, 5, 0b100000011001110},
+		{Q, 0, 0b011010101011111},
+		{H, 0, 0b001011010001001},
+	}
+	for _, test := range tests {
+		if got := formatBits(test.level, test.mask); got != test.want {
+			t.Errorf("formatBits(%d, %d) = %015b, want %015b", test.level, test.mask, got, test.want)
+		}
+	}
+}
+
+func TestVersionBits(t *testing.T) {
+	tests := []struct {
+		version int
+		want    int
+	}{
+		{7, 0b000111110010010100},
+		{8, 0b001000010110111100},
+		{21, 0b010101011010000011},
+		{40, 0b101000110001101001},
+	}
+	for _, test := range tests {
+		if got := versionBits(test.version); got != test.want {
+			t.Errorf("versionBits(%d) = %018b, want %018b", test.version, got, test.want)
+		}
+	}
+}
+
+// TestBlockGroups checks each version's blocks fill the modules left by the
+// function patterns, with the same number of error correction codewords in
+// every block, and spot checks the data capacities.
+func TestBlockGroups(t *testing.T) {
+	for version := minVersion; version <= maxVersion; version++ {
+		free := 0
+		for _, function := range newMatrix(version).function {
+			if !function {
+				free++
+			}
+		}
+		for level := L; level <= H; level++ {
+			total, ec := 0, -1
+			for _, group := range blockGroups[version-1][level] {
+				total += group.count * group.total
+				if ec >= 0 && group.total-group.data != ec {
+					t.Errorf("version %d level %d: blocks have different error correction lengths", version, level)
+				}
+				ec = group.total - group.data
+			}
+			if total != free/8 {
+				t.Errorf("version %d level %d: %d codewords, want %d", version, level, total, free/8)
+			}
+		}
+	}
+
+	capacities := map[int][4]int{
+		1:  {19, 16, 13, 9},
+		10: {274, 216, 154, 122},
+		15: {523, 415, 295, 223},
+		20: {861, 669, 485, 385},
+		40: {2956, 2334, 1666, 1276},
+	}
+	for version, want := range capacities {
+		for level := L; level <= H; level++ {
+			if got := dataCodewords(version, level); got != want[level] {
+				t.Errorf("dataCodewords(%d, %d) = %d, want %d", version, level, got, want[level])
+			}
+		}
+	}
+}
+
+// readFormat reads the two copies of the format information from a symbol:
+// the first around the top-left finder pattern, bit 14 at the left edge,
+// and the second split between the top-right and bottom-left finders.
+func readFormat(c *Code) (first, second int) {
+	for i := 14; i >= 0; i-- {
+		var x, y int
+		switch {
+		case i >= 9:
+			x, y = 14-i, 8
+		case i >= 7:
+			x, y = 15-i, 8
+		case i == 6:
+			x, y = 8, 7
+		default:
+			x, y = 8, i
+		}
+		first = first<<1 | bit(c.Dark(x, y))
+		if i >= 8 {
+			x, y = 8, c.Size-15+i
+		} else {
+			x, y = c.Size-1-i, 8
+		}
+		second = second<<1 | bit(c.Dark(x, y))
+	}
+	return first, second
+}
+
+func bit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
+
+func TestEncodePlacesFormatAndVersion(t *testing.T) {
+	tests := []struct {
+		data    []byte
+		level   Level
+		version int
+	}{
+		{[]byte("A"), M, 1},
+		{bytes.Repeat([]byte("x"), 17), L, 1},
+		{bytes.Repeat([]byte("x"), 18), L, 2},
+		{bytes.Repeat([]byte("x"), 98), H, 9},
+		{bytes.Repeat([]byte("x"), 220), H, 15},
+		{bytes.Repeat([]byte("x"), 2953), L, 40},
+	}
+	for _, test := range tests {
+		c, err := Encode(test.data, test.level)
+		if err != nil {
+			t.Errorf("Encode(%d bytes, %d) error: %v", len(test.data), test.level, err)
+			continue
+		}
+		if c.Version != test.version || c.Size != 4*test.version+17 {
+			t.Errorf("Encode(%d bytes, %d) = version %d size %d, want version %d", len(test.data), test.level, c.Version, c.Size, test.version)
+			continue
+		}
+		want := formatBits(test.level, c.Mask)
+		if first, second := readFormat(c); first != want || second != want {
+			t.Errorf("version %d format information %015b and %015b, want %015b", c.Version, first, second, want)
+		}
+		if !c.Dark(8, c.Size-8) {
+			t.Errorf("version %d: no dark module", c.Version)
+		}
+		if c.Version < 7 {
+			continue
+		}
+		// Version information: bit i is at row i/3, column Size-11+i%3 in the
+		// top-right block, transposed in the bottom-left one
+		upper, lower := 0, 0
+		for i := 17; i >= 0; i-- {
+			upper = upper<<1 | bit(c.Dark(c.Size-11+i%3, i/3))
+			lower = lower<<1 | bit(c.Dark(i/3, c.Size-11+i%3))
+		}
+		if want := versionBits(c.Version); upper != want || lower != want {
+			t.Errorf("version %d version information %018b and %018b, want %018b", c.Version, upper, lower, want)
+		}
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := Encode(bytes.Repeat([]byte("x"), 2954), L); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode(2954 bytes, L) error = %v, want ErrTooLong", err)
+	}
+	if _, err := Encode([]byte("x"), H+1); err == nil {
+		t.Error("Encode with an invalid level: no error")
+	}
+}
@@ -0,0 +1,66 @@
+package qr
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// QuietZone is the light border, in modules, that scanners need around a
+// symbol. The renderers include it.
+const QuietZone = 4
+
+// Runs calls fn for each horizontal run of dark modules, with x and y in
+// modules from the symbol's top-left corner.
+func (c *Code) Runs(fn func(x, y, length int)) {
+	for y := range c.Size {
+		for x := 0; x < c.Size; {
+			if !c.Dark(x, y) {
+				x++
+				continue
+			}
+			start := x
+			for x < c.Size && c.Dark(x, y) {
+				x++
+			}
+			fn(start, y, x-start)
+		}
+	}
+}
+
+// Image returns the symbol and its quiet zone with each module scale pixels
+// square.
+func (c *Code) Image(scale int) *image.Paletted {
+	scale = max(scale, 1)
+	side := (c.Size + 2*QuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	c.Runs(func(x, y, length int) {
+		for py := (QuietZone + y) * scale; py < (QuietZone+y+1)*scale; py++ {
+			for px := (QuietZone + x) * scale; px < (QuietZone+x+length)*scale; px++ {
+				img.SetColorIndex(px, py, 1)
+			}
+		}
+	})
+	return img
+}
+
+func (c *Code) WritePNG(w io.Writer, scale int) error {
+	return png.Encode(w, c.Image(scale))
+}
+
+// WriteSVG writes the symbol as a scalable SVG with one unit per module,
+// drawn as a single path.
+func (c *Code) WriteSVG(w io.Writer) error {
+	side := c.Size + 2*QuietZone
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, side, side)
+	c.Runs(func(x, y, length int) {
+		fmt.Fprintf(bw, "M%d %dh%dv1h-%dz", QuietZone+x, QuietZone+y, length, length)
+	})
+	bw.WriteString(`"/></svg>`)
+	return bw.Flush()
+}
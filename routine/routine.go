@@ -0,0 +1,140 @@
+// Package routine validates trampoline routines: names and tariffs of the
+// skills, transitions and landings between them, duplicates, the ten skill
+// limit and category profile restrictions. It also suggests the smallest
+// edits that repair a routine.
+package routine
+
+import (
+	"fmt"
+	"slices"
+
+	"tariffCalculator/categories"
+	"tariffCalculator/skills"
+)
+
+// Routine is a trampoline routine, its skills in the order they are
+// performed.
+type Routine []skills.TrampolineSkill
+
+// MaxLength is the longest routine accepted from a request. A competition
+// routine has ten skills; the rest is room for one being edited. Suggest
+// validates the whole routine again for each candidate edit, so requests
+// are checked with CheckLength before either is called.
+const MaxLength = 64
+
+// CheckLength returns an error if the routine has more than MaxLength skills.
+func (routine Routine) CheckLength() error {
+	if len(routine) > MaxLength {
+		return fmt.Errorf("routine has %d skills, at most %d are allowed", len(routine), MaxLength)
+	}
+	return nil
+}
+
+// Options selects the rules Validate applies. Nil fields use the defaults.
+type Options struct {
+	Rules   skills.TariffRules
+	Profile *categories.Profile
+}
+
+// SkillName returns the catalogue name of a skill, with the shape added
+// where it distinguishes skills, or a descriptive name for skills not in
+// the catalogue.
+func SkillName(parsedSkill skills.TrampolineSkill) string {
+	compareSkill := parsedSkill // Use the input skill directly for checks
+
+	// Ensure twist distribution slice length is correct based on rotation for comparison
+	expectedPhases := skills.CalculatePhases(compareSkill.Rotation)
+	if len(compareSkill.TwistDistribution) > expectedPhases {
+		compareSkill.TwistDistribution = compareSkill.TwistDistribution[:expectedPhases]
+	} else {
+		for len(compareSkill.TwistDistribution) < expectedPhases {
+			compareSkill.TwistDistribution = append(compareSkill.TwistDistribution, 0)
+		}
+	}
+
+	// Iterate through the live common skill catalogue
+	for _, commonSkill := range skills.Catalogue() {
+		tempCommon := commonSkill // Work with a copy
+
+		// Ensure common skill twist distribution is also correct length for comparison
+		commonExpectedPhases := skills.CalculatePhases(tempCommon.Rotation)
+		if len(tempCommon.TwistDistribution) > commonExpectedPhases {
+			tempCommon.TwistDistribution = tempCommon.TwistDistribution[:commonExpectedPhases]
+		} else {
+			for len(tempCommon.TwistDistribution) < commonExpectedPhases {
+				tempCommon.TwistDistribution = append(tempCommon.TwistDistribution, 0)
+			}
+		}
+
+		// --- Core Parameter Check (Ignoring Shape initially) ---
+		// Check Rotation, Takeoff, Backward, SeatLanding, and Twist Distribution
+		// Note: Using slices.Equal for twist distribution comparison.
+		if compareSkill.Rotation == tempCommon.Rotation &&
+			compareSkill.TakeoffPosition == tempCommon.TakeoffPosition &&
+			compareSkill.Backward == tempCommon.Backward &&
+			compareSkill.SeatLanding == tempCommon.SeatLanding &&
+			slices.Equal(compareSkill.TwistDistribution, tempCommon.TwistDistribution) {
+
+			// Found a match based on core parameters! Now check shape.
+			baseName := tempCommon.Name
+			inputShape := compareSkill.Shape
+			defaultShape := tempCommon.Shape // Shape stored in the CommonSkills map entry
+
+			// Determine if shape matters for uniqueness based on FIG rules
+			shapeMatters := false
+			rotation := compareSkill.Rotation
+			totalTwist := compareSkill.TotalTwist() // Use the method from skills.go
+
+			if rotation == 0 && totalTwist == 0 && compareSkill.LandingPosition() != skills.Seat && compareSkill.TakeoffPosition != skills.Seat { // Basic Jumps
+				// Shape always matters for non-straight basic jumps
+				if baseName == "Shape Jump" && (inputShape == skills.Tuck || inputShape == skills.Pike || inputShape == skills.Straddle) {
+					return fmt.Sprintf("%s Jump", inputShape.String())
+				} else {
+					return "Straight Jump"
+				}
+				// For straight jump, shape doesn't result in appending name
+			} else if rotation >= 6 { // Doubles+
+				shapeMatters = true
+			} else if rotation >= 3 && totalTwist < 2 { // Singles/Crash/Lazy with < Full twist
+				shapeMatters = true
+			}
+			// Note: For Rotation < 3 (Front/Back drops) or Rotation 3-5 with >= Full twist, shapeMatters remains false.
+
+			// Append shape name ONLY if it matters AND it's different from the default
+			if shapeMatters && (defaultShape != skills.Straight || defaultShape != inputShape) {
+				// Append the actual shape name
+				return fmt.Sprintf("%s %s", baseName, inputShape.String())
+			} else {
+				// Return the base name (shape didn't matter, or it matched the default)
+				return baseName
+			}
+		}
+	}
+
+	// No common skill match found, fall back to a conventional name
+	if name := skills.DescriptiveName(&compareSkill); name != "" {
+		return name
+	}
+	return "Custom Skill"
+}
+
+// Prepare ensures twist lengths and names are correct in the routine before
+// validation. Skills loaded from storage or a request may have either wrong.
+func (r Routine) Prepare() {
+	for i := range r {
+		NormalizeTwists(&r[i])
+		r[i].Name = SkillName(r[i])
+	}
+}
+
+// NormalizeTwists trims or zero-pads the twist phases to match the rotation.
+func NormalizeTwists(skill *skills.TrampolineSkill) {
+	expectedPhases := skills.CalculatePhases(skill.Rotation)
+	if len(skill.TwistDistribution) > expectedPhases {
+		skill.TwistDistribution = skill.TwistDistribution[:expectedPhases]
+	} else {
+		for len(skill.TwistDistribution) < expectedPhases {
+			skill.TwistDistribution = append(skill.TwistDistribution, 0)
+		}
+	}
+}
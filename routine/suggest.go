@@ -0,0 +1,204 @@
+package routine
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"tariffCalculator/skills"
+)
+
+const maxSuggestionsPerProblem = 3
+
+// Suggestion is a single edit that fixes a problem reported by Validate.
+type Suggestion struct {
+	Index        int                    `json:"index"`   // Skill the problem was reported on
+	Problem      string                 `json:"problem"` // e.g. "Bad Transition: Back -> Feet"
+	Action       string                 `json:"action"`  // "replace" or "insert"
+	Position     int                    `json:"position"`
+	Skill        skills.TrampolineSkill `json:"skill"` // The replacement or inserted skill
+	Description  string                 `json:"description"`
+	TariffChange float64                `json:"tariffChange"` // Change to the routine's TotalTariff
+}
+
+// Suggest proposes minimal edits for every bad transition, invalid landing
+// and tenth skill not landing on feet in data, the routine's validation.
+// Candidates are near variants of the skills involved (one more or less
+// quarter somersault, half twist in one phase, seat landing or takeoff) and
+// connectors from the common skill catalogue. A candidate is kept if the
+// edited routine has fewer problems, and each problem's suggestions are
+// ranked by their effect on the total tariff, best first.
+func (routine Routine) Suggest(data Result, opts Options) []Suggestion {
+	suggestions := []Suggestion{}
+	problems := routineProblemCount(data)
+	if problems == 0 {
+		return suggestions
+	}
+
+	// try validates the routine with one edit applied and returns the
+	// suggestion for it, or false if it does not reduce the problems.
+	try := func(index int, problem, action string, position int, skill skills.TrampolineSkill) (Suggestion, bool) {
+		edited := slices.Clone(routine)
+		if action == "insert" {
+			edited = slices.Insert(edited, position, skill)
+		} else {
+			edited[position] = skill
+		}
+		edited.Prepare()
+		result := edited.Validate(opts)
+		if routineProblemCount(result) >= problems {
+			return Suggestion{}, false
+		}
+		suggested := result.Skills[position].TrampolineSkill
+		suggested.LandingPosStr = result.Skills[position].LandingPosStr
+		description := fmt.Sprintf("Replace %d. %s with %s %s", position+1, routine[position].Name, suggested.Name, result.Skills[position].FIGNotation)
+		if action == "insert" {
+			description = fmt.Sprintf("Insert %s %s before %d.", suggested.Name, result.Skills[position].FIGNotation, position+1)
+		}
+		return Suggestion{
+			Index:        index,
+			Problem:      problem,
+			Action:       action,
+			Position:     position,
+			Skill:        suggested,
+			Description:  description,
+			TariffChange: math.Round((result.TotalTariff-data.TotalTariff)*10) / 10,
+		}, true
+	}
+
+	for i, validated := range data.Skills {
+		var found []Suggestion
+		add := func(suggestion Suggestion, ok bool) {
+			if ok && !slices.ContainsFunc(found, func(existing Suggestion) bool { return existing.Description == suggestion.Description }) {
+				found = append(found, suggestion)
+			}
+		}
+
+		if validated.InvalidTransition {
+			from := data.Skills[i-1].LandingPosition()
+			to := validated.TakeoffPosition
+			problem := fmt.Sprintf("Bad Transition: %s -> %s", from, to)
+			// Change this skill to take off from the previous landing
+			for _, variant := range skillVariants(routine[i], from) {
+				add(try(i, problem, "replace", i, variant))
+			}
+			// Change the previous skill to land where this one takes off
+			for _, variant := range skillVariants(routine[i-1], routine[i-1].TakeoffPosition) {
+				if variant.LandingPosition() == to {
+					add(try(i, problem, "replace", i-1, variant))
+				}
+			}
+			// Insert a connector between them
+			for _, connector := range skills.Catalogue() {
+				if connector.TakeoffPosition == from && connector.LandingPosition() == to {
+					add(try(i, problem, "insert", i, connector))
+				}
+			}
+		}
+
+		if validated.InvalidLanding || (i == 9 && data.TenthSkillWarning) {
+			problem := "Invalid Landing"
+			if !validated.InvalidLanding {
+				problem = "10th Must Land Feet"
+			}
+			for _, variant := range skillVariants(routine[i], routine[i].TakeoffPosition) {
+				add(try(i, problem, "replace", i, variant))
+			}
+		}
+
+		suggestions = append(suggestions, rankSuggestions(found)...)
+	}
+	return suggestions
+}
+
+// rankSuggestions orders one problem's suggestions by tariff change and keeps
+// the best maxSuggestionsPerProblem, always including the best of each kind
+// of edit so a cheap connector is not hidden by higher-tariff replacements.
+func rankSuggestions(found []Suggestion) []Suggestion {
+	sort.SliceStable(found, func(a, b int) bool { return found[a].TariffChange > found[b].TariffChange })
+	var ranked, rest []Suggestion
+	kinds := map[string]bool{}
+	for _, suggestion := range found {
+		kind := fmt.Sprintf("%s %d", suggestion.Action, suggestion.Position)
+		if !kinds[kind] {
+			kinds[kind] = true
+			ranked = append(ranked, suggestion)
+		} else {
+			rest = append(rest, suggestion)
+		}
+	}
+	ranked = append(ranked, rest...)
+	if len(ranked) > maxSuggestionsPerProblem {
+		ranked = ranked[:maxSuggestionsPerProblem]
+	}
+	sort.SliceStable(ranked, func(a, b int) bool { return ranked[a].TariffChange > ranked[b].TariffChange })
+	return ranked
+}
+
+// routineProblemCount counts the problems Suggest tries to fix.
+func routineProblemCount(data Result) int {
+	count := 0
+	for i := range data.Skills {
+		if data.Skills[i].InvalidTransition {
+			count++
+		}
+		if data.Skills[i].InvalidLanding {
+			count++
+		}
+	}
+	if data.TenthSkillWarning {
+		count++
+	}
+	return count
+}
+
+// skillVariants returns the skills one small edit away from skill, all taking
+// off from takeoff: the skill itself (if the takeoff differs), one quarter
+// somersault more or less (from 0 to skills.MaxRotation), one half twist
+// more or less in a single phase, and the seat landing toggled.
+func skillVariants(skill skills.TrampolineSkill, takeoff skills.BodyPosition) []skills.TrampolineSkill {
+	base := skill
+	base.TakeoffPosition = takeoff
+	base.TwistDistribution = slices.Clone(skill.TwistDistribution)
+	base.Tariff = 0
+	base.LandingPosStr = ""
+
+	var variants []skills.TrampolineSkill
+	addVariant := func(variant skills.TrampolineSkill) {
+		NormalizeTwists(&variant)
+		if variant.Rotation == 0 {
+			variant.Backward = false // Jumps have no direction
+		}
+		if variant.LandingPosition() != skills.Invalid {
+			variants = append(variants, variant)
+		}
+	}
+
+	if takeoff != skill.TakeoffPosition {
+		addVariant(base)
+	}
+	for _, change := range []int{-1, 1} {
+		variant := base
+		variant.TwistDistribution = slices.Clone(base.TwistDistribution)
+		variant.Rotation += change
+		if variant.Rotation >= 0 && variant.Rotation <= skills.MaxRotation {
+			addVariant(variant)
+		}
+	}
+	for phase := range base.TwistDistribution {
+		for _, change := range []int{-1, 1} {
+			variant := base
+			variant.TwistDistribution = slices.Clone(base.TwistDistribution)
+			variant.TwistDistribution[phase] += change
+			if variant.TwistDistribution[phase] >= 0 {
+				addVariant(variant)
+			}
+		}
+	}
+	toggled := base
+	toggled.TwistDistribution = slices.Clone(base.TwistDistribution)
+	toggled.SeatLanding = !toggled.SeatLanding
+	addVariant(toggled)
+	return variants
+}
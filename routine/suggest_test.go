@@ -0,0 +1,24 @@
+package routine
+
+import (
+	"testing"
+
+	"tariffCalculator/skills"
+)
+
+func TestSkillVariantsRotationRange(t *testing.T) {
+	tests := []struct {
+		name  string
+		skill skills.TrampolineSkill
+	}{
+		{"straight jump", skills.TrampolineSkill{Shape: skills.Straight, TwistDistribution: []int{0}}},
+		{"quadruple back", skills.TrampolineSkill{Rotation: skills.MaxRotation, Backward: true, Shape: skills.Tuck, TwistDistribution: []int{0, 0, 0, 0}}},
+	}
+	for _, test := range tests {
+		for _, variant := range skillVariants(test.skill, skills.Feet) {
+			if variant.Rotation < 0 || variant.Rotation > skills.MaxRotation {
+				t.Errorf("%s: variant %s has rotation %d", test.name, variant.FIGNotation(), variant.Rotation)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package routine
+
+import (
+	"fmt"
+	"strings"
+
+	"tariffCalculator/categories"
+	"tariffCalculator/skills"
+)
+
+// SkillResult is one skill of a validated routine with the problems found
+// on it.
+type SkillResult struct {
+	skills.TrampolineSkill
+	InvalidTransition bool   `json:"-"`
+	InvalidLanding    bool   `json:"-"`
+	IsDuplicate       bool   `json:"-"`
+	LandingPosStr     string `json:"landing_position"`
+	SkillDataJSON     string `json:"-"`
+	FIGNotation       string `json:"FIGNotation"`
+}
+
+// IssueKind identifies a kind of problem found by Validate.
+type IssueKind string
+
+const (
+	Duplicate         IssueKind = "duplicate"           // Repeats an earlier skill, which counts once
+	BadTransition     IssueKind = "bad-transition"      // Takes off from where the previous skill did not land
+	InvalidLanding    IssueKind = "invalid-landing"     // Lands in no valid position
+	TenthSkillLanding IssueKind = "tenth-skill-landing" // The tenth skill does not land on feet
+	ExtraSkill        IssueKind = "extra-skill"         // Beyond the tenth skill, scores no tariff
+	ProfileViolation  IssueKind = "profile-violation"   // Breaks a category profile restriction
+)
+
+// Issue is one problem found by Validate. Skill is the index of the skill it
+// was found on, or -1 for problems with the routine as a whole.
+type Issue struct {
+	Kind    IssueKind `json:"kind"`
+	Skill   int       `json:"skill"`
+	Message string    `json:"message"`
+}
+
+// Result is the validation of a routine: its skills, tariff and problems.
+// Messages has one entry per skill, empty when the skill has no problems,
+// and Issues lists the same problems, plus the routine-level ones, as
+// values programs can check.
+type Result struct {
+	Skills                []SkillResult `json:"skills"`
+	TotalTariff           float64       `json:"totalTariff"`
+	RawTariff             float64       `json:"rawTariff"`
+	HasDuplicates         bool          `json:"hasDuplicates"`
+	HasInvalidTransitions bool          `json:"hasInvalidTransitions"`
+	HasInvalidLandings    bool          `json:"hasInvalidLandings"`
+	TenthSkillWarning     bool          `json:"tenthSkillWarning"`
+	RoutineTooLong        bool          `json:"routineTooLong"`
+	Messages              []string      `json:"messages"`
+	Rules                 string        `json:"rules"`   // ID of the tariff rule set used
+	Profile               string        `json:"profile"` // ID of the category profile used
+	HasProfileViolations  bool          `json:"hasProfileViolations"`
+	ProfileViolations     []string      `json:"profileViolations"` // Routine-level category violations
+	Issues                []Issue       `json:"issues"`
+	Suggestions           []Suggestion  `json:"suggestions"` // Filled in by callers that suggest repairs
+}
+
+// Valid reports whether the routine can be competed as it stands: every
+// transition and landing works, the tenth skill lands on feet, there are no
+// more than ten skills and the category profile is met. Duplicates are
+// allowed; they only count once.
+func (data Result) Valid() bool {
+	return !data.HasInvalidTransitions && !data.HasInvalidLandings && !data.TenthSkillWarning &&
+		!data.RoutineTooLong && !data.HasProfileViolations
+}
+
+// Validate checks the routine and returns its tariff and problems. Tariffs
+// are recalculated with opts.Rules and opts.Profile adds the category's
+// restrictions to the senior routine rules. Call Prepare first if the skill
+// names or twist phases may be stale; the routine itself is not changed.
+func (routine Routine) Validate(opts Options) Result {
+	rules := opts.Rules
+	if rules == nil {
+		rules = skills.DefaultTariffRules()
+	}
+	profile := opts.Profile
+	if profile == nil {
+		profile = categories.DefaultProfile()
+	}
+	data := Result{
+		Rules:                 rules.ID(),
+		Profile:               profile.ID,
+		ProfileViolations:     []string{},
+		Issues:                []Issue{},
+		Suggestions:           []Suggestion{},
+		Skills:                make([]SkillResult, len(routine)),
+		Messages:              make([]string, len(routine)),
+		HasDuplicates:         false,
+		HasInvalidTransitions: false,
+		HasInvalidLandings:    false,
+		TenthSkillWarning:     false,
+		RoutineTooLong:        len(routine) > 10,
+		TotalTariff:           0.0,
+		RawTariff:             0.0,
+	}
+
+	duplicateMap := make(map[int]bool)
+	validSkillCount := 0
+	var countedSkills []skills.TrampolineSkill
+
+	for i := range routine {
+		data.Skills[i].TrampolineSkill = routine[i] // Already has correct twist length and name from caller
+		data.Skills[i].SetTariff(rules)
+		landing := data.Skills[i].LandingPosition()
+		data.Skills[i].LandingPosStr = landing.String()
+
+		data.RawTariff += data.Skills[i].Tariff
+		data.Skills[i].FIGNotation = data.Skills[i].TrampolineSkill.FIGNotation() // Calculate and store
+
+		var messages []string
+		addIssue := func(kind IssueKind, message string) {
+			messages = append(messages, message)
+			data.Issues = append(data.Issues, Issue{Kind: kind, Skill: i, Message: message})
+		}
+		isCurrentSkillDuplicate := false
+		for j := 0; j < i; j++ {
+			// Use the Equal method which compares based on rules
+			if data.Skills[i].Equal(&data.Skills[j].TrampolineSkill) {
+				isCurrentSkillDuplicate = true
+				data.HasDuplicates = true
+
+				if _, marked := duplicateMap[j]; !marked {
+					data.Skills[j].IsDuplicate = true
+					duplicateMap[j] = true
+					if data.Messages[j] == "" {
+						data.Messages[j] = "Duplicate (Counts Once)"
+					} else {
+						data.Messages[j] += " / Duplicate (Counts Once)"
+					}
+					data.Issues = append(data.Issues, Issue{Kind: Duplicate, Skill: j, Message: "Duplicate (Counts Once)"})
+				}
+				data.Skills[i].IsDuplicate = true
+				addIssue(Duplicate, "Duplicate")
+				break
+			}
+		}
+
+		if !isCurrentSkillDuplicate && validSkillCount < 10 {
+			data.TotalTariff += data.Skills[i].Tariff
+			validSkillCount++
+			countedSkills = append(countedSkills, data.Skills[i].TrampolineSkill)
+		}
+
+		if violations := profile.CheckSkill(&data.Skills[i].TrampolineSkill); len(violations) > 0 {
+			data.HasProfileViolations = true
+			for _, violation := range violations {
+				addIssue(ProfileViolation, violation)
+			}
+		}
+
+		if i > 0 {
+			prevLanding := data.Skills[i-1].LandingPosition()
+			currentTakeoff := data.Skills[i].TakeoffPosition
+			if prevLanding != skills.Invalid && prevLanding != currentTakeoff {
+				data.Skills[i].InvalidTransition = true
+				data.HasInvalidTransitions = true
+				if i < 10 || !data.RoutineTooLong {
+					addIssue(BadTransition, fmt.Sprintf("Bad Transition: %s -> %s", prevLanding.String(), currentTakeoff.String()))
+				}
+			}
+		}
+
+		if landing == skills.Invalid {
+			data.Skills[i].InvalidLanding = true
+			data.HasInvalidLandings = true
+			if i < 10 || !data.RoutineTooLong {
+				addIssue(InvalidLanding, "Invalid Landing")
+			}
+		}
+
+		if i == 9 {
+			if landing != skills.Feet {
+				data.TenthSkillWarning = true
+				addIssue(TenthSkillLanding, "10th Must Land Feet")
+			}
+		}
+
+		if i >= 10 {
+			addIssue(ExtraSkill, "Skill >10 (No Tariff)")
+		}
+
+		data.Messages[i] = strings.Join(messages, " / ")
+	}
+
+	data.ProfileViolations = append(data.ProfileViolations, profile.CheckRoutine(countedSkills, data.TotalTariff)...)
+	for _, violation := range data.ProfileViolations {
+		data.Issues = append(data.Issues, Issue{Kind: ProfileViolation, Skill: -1, Message: violation})
+	}
+	if len(data.ProfileViolations) > 0 {
+		data.HasProfileViolations = true
+	}
+
+	return data
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"tariffCalculator/accounts"
+)
+
+const sessionCookieName = "session"
+
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// AccountInfo is the signed-in user as shown to pages and by /account/me.
+type AccountInfo struct {
+	Username string        `json:"username"`
+	Role     accounts.Role `json:"role"`
+	Coaches  []string      `json:"coaches"` // Athletes: coaches they share routines with
+	Squad    []string      `json:"squad"`   // Coaches: athletes sharing routines with them
+}
+
+func (s *Server) newAccountInfo(user accounts.User) *AccountInfo {
+	info := &AccountInfo{Username: user.Username, Role: user.Role, Coaches: user.Coaches, Squad: []string{}}
+	if user.Role == accounts.Coach {
+		info.Squad = s.accounts.Squad(user.Username)
+	}
+	return info
+}
+
+// --- Sessions ---
+
+// withSession makes every handler session-aware by putting the signed-in
+// user, if any, in the request context for currentUser.
+func (s *Server) withSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie(sessionCookieName); err == nil {
+			if user, ok := s.accounts.SessionUser(cookie.Value); ok {
+				r = r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// currentUser returns the user signed in for the request.
+func currentUser(r *http.Request) (accounts.User, bool) {
+	user, ok := r.Context().Value(userContextKey).(accounts.User)
+	return user, ok
+}
+
+// requireUser returns the signed-in user, or answers 401 and reports false.
+func requireUser(w http.ResponseWriter, r *http.Request) (accounts.User, bool) {
+	user, ok := currentUser(r)
+	if !ok {
+		http.Error(w, "Unauthorized: sign in first", 401)
+	}
+	return user, ok
+}
+
+func (s *Server) setSessionCookie(w http.ResponseWriter, r *http.Request, token string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    token,
+		Path:     s.path("/"),
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// --- Account Handlers ---
+
+func (s *Server) handleAccountPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "account", s.newIndexPageData(r))
+}
+
+// handleAccount dispatches the account API:
+//
+//	GET    /account/me                the signed-in user's AccountInfo
+//	POST   /account/register          {"username", "password", "role"}, signs in; coaches and athletes only
+//	POST   /account/login             {"username", "password"}
+//	POST   /account/logout
+//	POST   /account/coaches           {"coach"}, athletes share their routines with a coach
+//	DELETE /account/coaches/{coach}   athletes stop sharing with a coach
+//	DELETE /account/squad/{athlete}   coaches remove an athlete from their squad
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	route, name, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/account/"), "/")
+
+	switch {
+	case route == "register" && r.Method == http.MethodPost:
+		var request struct {
+			Username string        `json:"username"`
+			Password string        `json:"password"`
+			Role     accounts.Role `json:"role"`
+		}
+		if !decodeJSONBody(w, r, &request) {
+			return
+		}
+		if request.Role == accounts.Organiser {
+			http.Error(w, "Forbidden: organiser accounts are created with the tariff organiser command", 403)
+			return
+		}
+		user, err := s.accounts.Register(strings.ToLower(strings.TrimSpace(request.Username)), request.Password, request.Role)
+		if err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), 400)
+			return
+		}
+		log.Printf("Registered %s account %s", user.Role, user.Username)
+		s.startSession(w, r, user)
+	case route == "login" && r.Method == http.MethodPost:
+		var request struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if !decodeJSONBody(w, r, &request) {
+			return
+		}
+		user, err := s.accounts.Authenticate(strings.ToLower(strings.TrimSpace(request.Username)), request.Password)
+		if errors.Is(err, accounts.ErrInvalidCredentials) {
+			http.Error(w, "Unauthorized: "+err.Error(), 401)
+			return
+		}
+		if err != nil {
+			log.Printf("Error authenticating %q: %v", request.Username, err)
+			http.Error(w, "Internal Server Error", 500)
+			return
+		}
+		s.startSession(w, r, user)
+	case route == "logout" && r.Method == http.MethodPost:
+		if cookie, err := r.Cookie(sessionCookieName); err == nil {
+			s.accounts.EndSession(cookie.Value)
+		}
+		s.setSessionCookie(w, r, "", -1)
+		w.WriteHeader(http.StatusNoContent)
+	case route == "me" && r.Method == http.MethodGet:
+		if user, ok := requireUser(w, r); ok {
+			writeJSON(w, s.newAccountInfo(user))
+		}
+	case route == "coaches" && name == "" && r.Method == http.MethodPost:
+		user, ok := requireUser(w, r)
+		if !ok {
+			return
+		}
+		var request struct {
+			Coach string `json:"coach"`
+		}
+		if !decodeJSONBody(w, r, &request) {
+			return
+		}
+		if err := s.accounts.LinkCoach(user.Username, strings.ToLower(strings.TrimSpace(request.Coach))); err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), 400)
+			return
+		}
+		s.writeAccountInfo(w, user.Username)
+	case route == "coaches" && name != "" && r.Method == http.MethodDelete:
+		if user, ok := requireUser(w, r); ok {
+			s.unlinkCoach(w, user.Username, name, user.Username)
+		}
+	case route == "squad" && name != "" && r.Method == http.MethodDelete:
+		if user, ok := requireUser(w, r); ok {
+			s.unlinkCoach(w, name, user.Username, user.Username)
+		}
+	default:
+		http.Error(w, "Not Found", 404)
+	}
+}
+
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, user accounts.User) {
+	s.setSessionCookie(w, r, s.accounts.NewSession(user.Username), int(accounts.SessionLifetime.Seconds()))
+	writeJSON(w, s.newAccountInfo(user))
+}
+
+func (s *Server) unlinkCoach(w http.ResponseWriter, athlete, coach, username string) {
+	if err := s.accounts.UnlinkCoach(athlete, coach); err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	s.writeAccountInfo(w, username)
+}
+
+// writeAccountInfo answers with a user's updated AccountInfo.
+func (s *Server) writeAccountInfo(w http.ResponseWriter, username string) {
+	user, ok := s.accounts.User(username)
+	if !ok {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+	writeJSON(w, s.newAccountInfo(user))
+}
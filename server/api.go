@@ -0,0 +1,476 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"tariffCalculator/categories"
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+	"tariffCalculator/storage"
+)
+
+// The /api/v1 endpoints are the stable JSON interface for other tools, such
+// as club websites. Unlike the page endpoints they take JSON bodies only,
+// reject unknown fields and answer every error with a Problem. The OpenAPI
+// description in static/openapi.json is served at /api/v1/openapi.json and
+// must be kept in step with this file.
+const (
+	apiPrefix       = "/api/v1/"
+	maxAPIBodyBytes = 1 << 20
+)
+
+// Problem is the body of every /api/v1 error response, as RFC 9457
+// describes.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// requestError is an error the client caused, answered with status.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string { return e.err.Error() }
+func (e *requestError) Unwrap() error { return e.err }
+
+func badRequest(err error) error {
+	return &requestError{status: 400, err: err}
+}
+
+// --- API Types ---
+
+// APIOption is a rule set or category profile.
+type APIOption struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// APICommonSkill is a catalogue skill with its tariff under the requested
+// rules.
+type APICommonSkill struct {
+	Key string `json:"key"`
+	CalculatedSkill
+}
+
+type APISkillRequest struct {
+	Rules string                 `json:"rules"`
+	Skill skills.TrampolineSkill `json:"skill"`
+}
+
+// APINotationRequest is a skill in FIG notation. The notation carries no
+// direction or takeoff position, so those are given alongside it.
+type APINotationRequest struct {
+	Rules           string              `json:"rules"`
+	Notation        string              `json:"notation"`
+	TakeoffPosition skills.BodyPosition `json:"takeoff_position"`
+	Backward        bool                `json:"backward"`
+	SeatLanding     bool                `json:"seat_landing"`
+}
+
+type APIValidationRequest struct {
+	Rules   string                   `json:"rules"`
+	Profile string                   `json:"profile"`
+	Routine []skills.TrampolineSkill `json:"routine"`
+}
+
+type APIRoutineRequest struct {
+	Skills []skills.TrampolineSkill `json:"skills"`
+}
+
+// --- API Handler ---
+
+// handleAPI dispatches the /api/v1 resources:
+//
+//	GET    /api/v1/openapi.json
+//	GET    /api/v1/rules                       tariff rule sets
+//	GET    /api/v1/profiles                    category profiles
+//	POST   /api/v1/skills/calculate            APISkillRequest, answers a CalculatedSkill
+//	POST   /api/v1/skills/notation             APINotationRequest, answers a CalculatedSkill
+//	POST   /api/v1/skills/batch?rules=         APIBatchItem array or NDJSON, answers APIBatchResult per item
+//	GET    /api/v1/common-skills?rules=&sort=  list of APICommonSkill
+//	GET    /api/v1/common-skills/{key}?rules=
+//	POST   /api/v1/validation                  APIValidationRequest, answers routine.Result
+//	GET    /api/v1/routines?owner=             the signed-in user's saved routines
+//	GET    /api/v1/routines/{name}?owner=      a saved routine with its notes
+//	PUT    /api/v1/routines/{name}             APIRoutineRequest
+//	DELETE /api/v1/routines/{name}
+//	GET    /api/v1/routines/{name}/notes?owner=
+//	POST   /api/v1/routines/{name}/notes?owner=  {"skill", "text"}
+//
+// Routines use the session cookie from /account/login.
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	// Any site may call the API; browsers send no cookies with it cross-origin
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Use the escaped path so routine names may contain an encoded "/"
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), apiPrefix), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			writeAPIError(w, badRequest(err))
+			return
+		}
+		segments[i] = unescaped
+	}
+
+	switch segments[0] {
+	case "openapi.json":
+		if allowMethods(w, r, http.MethodGet) && requireSegments(w, segments, 1) {
+			w.Header().Set("Content-Type", "application/json")
+			http.ServeFileFS(w, r, s.static, "openapi.json")
+		}
+	case "rules":
+		if allowMethods(w, r, http.MethodGet) && requireSegments(w, segments, 1) {
+			options := []APIOption{}
+			for _, rules := range skills.TariffRuleSets() {
+				options = append(options, APIOption{ID: rules.ID(), Name: rules.Name()})
+			}
+			writeJSON(w, options)
+		}
+	case "profiles":
+		if allowMethods(w, r, http.MethodGet) && requireSegments(w, segments, 1) {
+			options := []APIOption{}
+			for _, profile := range categories.Profiles() {
+				options = append(options, APIOption{ID: profile.ID, Name: profile.Name})
+			}
+			writeJSON(w, options)
+		}
+	case "skills":
+		s.handleAPISkills(w, r, segments)
+	case "common-skills":
+		s.handleAPICommonSkills(w, r, segments)
+	case "validation":
+		if allowMethods(w, r, http.MethodPost) && requireSegments(w, segments, 1) {
+			s.handleAPIValidation(w, r)
+		}
+	case "routines":
+		s.handleAPIRoutines(w, r, segments)
+	default:
+		writeAPIProblem(w, 404, "no such resource")
+	}
+}
+
+func (s *Server) handleAPISkills(w http.ResponseWriter, r *http.Request, segments []string) {
+	if !requireSegments(w, segments, 2) || !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	var skill skills.TrampolineSkill
+	var rulesID string
+	switch segments[1] {
+	case "batch":
+		s.handleAPISkillBatch(w, r)
+		return
+	case "calculate":
+		var request APISkillRequest
+		if writeAPIError(w, decodeAPIBody(r, &request)) || writeAPIError(w, checkAPISkill(request.Skill, "skill")) {
+			return
+		}
+		skill, rulesID = request.Skill, request.Rules
+		routine.NormalizeTwists(&skill)
+	case "notation":
+		var request APINotationRequest
+		if writeAPIError(w, decodeAPIBody(r, &request)) {
+			return
+		}
+		parsed, err := skills.ParseFIGNotation(request.Notation)
+		if err != nil {
+			writeAPIError(w, badRequest(err))
+			return
+		}
+		skill, rulesID = parsed, request.Rules
+		skill.TakeoffPosition = request.TakeoffPosition
+		skill.Backward = request.Backward
+		skill.SeatLanding = request.SeatLanding
+		if writeAPIError(w, checkAPISkill(skill, "skill")) {
+			return
+		}
+	default:
+		writeAPIProblem(w, 404, "no such resource")
+		return
+	}
+	rules, err := s.lookupTariffRules(rulesID)
+	if err != nil {
+		writeAPIError(w, badRequest(err))
+		return
+	}
+	skill.Name = routine.SkillName(skill)
+	writeCalculatedSkill(w, &skill, rules)
+}
+
+func (s *Server) handleAPICommonSkills(w http.ResponseWriter, r *http.Request, segments []string) {
+	if !allowMethods(w, r, http.MethodGet) {
+		return
+	}
+	rules, err := s.lookupTariffRules(r.URL.Query().Get("rules"))
+	if err != nil {
+		writeAPIError(w, badRequest(err))
+		return
+	}
+	catalogue := skills.Catalogue()
+	commonSkill := func(key string) APICommonSkill {
+		skill := catalogue[key]
+		skill.TwistDistribution = append([]int(nil), skill.TwistDistribution...)
+		routine.NormalizeTwists(&skill)
+		return APICommonSkill{Key: key, CalculatedSkill: newCalculatedSkill(&skill, rules)}
+	}
+
+	switch {
+	case len(segments) == 1:
+		sortBy := r.URL.Query().Get("sort")
+		switch sortBy {
+		case "":
+			sortBy = "tariff-desc"
+		case "tariff-desc", "tariff-asc", "alpha-asc", "alpha-desc":
+		default:
+			writeAPIError(w, badRequest(fmt.Errorf("unknown sort %q, expected tariff-desc, tariff-asc, alpha-asc or alpha-desc", sortBy)))
+			return
+		}
+		list := []APICommonSkill{}
+		for _, entry := range getSortedCommonSkills(sortBy, rules) {
+			list = append(list, commonSkill(entry.Key))
+		}
+		writeJSON(w, list)
+	case len(segments) == 2:
+		if _, ok := catalogue[segments[1]]; !ok {
+			writeAPIProblem(w, 404, fmt.Sprintf("no common skill %q", segments[1]))
+			return
+		}
+		writeJSON(w, commonSkill(segments[1]))
+	default:
+		writeAPIProblem(w, 404, "no such resource")
+	}
+}
+
+func (s *Server) handleAPIValidation(w http.ResponseWriter, r *http.Request) {
+	var request APIValidationRequest
+	if writeAPIError(w, decodeAPIBody(r, &request)) {
+		return
+	}
+	if err := routine.Routine(request.Routine).CheckLength(); err != nil {
+		writeAPIError(w, badRequest(err))
+		return
+	}
+	for i, skill := range request.Routine {
+		if writeAPIError(w, checkAPISkill(skill, fmt.Sprintf("routine[%d]", i))) {
+			return
+		}
+	}
+	rules, err := s.lookupTariffRules(request.Rules)
+	if err != nil {
+		writeAPIError(w, badRequest(err))
+		return
+	}
+	profile, err := lookupProfile(request.Profile)
+	if err != nil {
+		writeAPIError(w, badRequest(err))
+		return
+	}
+	skillList := routine.Routine(request.Routine)
+	if skillList == nil {
+		skillList = routine.Routine{}
+	}
+	skillList.Prepare()
+	opts := routine.Options{Rules: rules, Profile: profile}
+	data := skillList.Validate(opts)
+	data.Suggestions = skillList.Suggest(data, opts)
+	writeJSON(w, data)
+}
+
+func (s *Server) handleAPIRoutines(w http.ResponseWriter, r *http.Request, segments []string) {
+	user, ok := currentUser(r)
+	if !ok {
+		writeAPIProblem(w, 401, "sign in with /account/login first")
+		return
+	}
+	owner, err := s.routineOwner(r, user)
+	if writeAPIError(w, err) {
+		return
+	}
+
+	switch {
+	case len(segments) == 1 || (len(segments) == 2 && segments[1] == ""):
+		if allowMethods(w, r, http.MethodGet) {
+			summaries, err := s.listRoutines(owner)
+			if !writeAPIError(w, err) {
+				writeJSON(w, summaries)
+			}
+		}
+	case len(segments) == 2:
+		if !allowMethods(w, r, http.MethodGet, http.MethodPut, http.MethodDelete) {
+			return
+		}
+		name := segments[1]
+		if r.Method != http.MethodGet && owner != user.Username {
+			writeAPIProblem(w, 403, "only the owner can change a routine")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			routine, err := s.routines.Get(owner, name)
+			if !writeAPIError(w, err) {
+				writeJSON(w, routine)
+			}
+		case http.MethodPut:
+			var request APIRoutineRequest
+			if writeAPIError(w, decodeAPIBody(r, &request)) {
+				return
+			}
+			if err := checkRoutineLength(request.Skills); err != nil {
+				writeAPIError(w, badRequest(err))
+				return
+			}
+			for i, skill := range request.Skills {
+				if writeAPIError(w, checkAPISkill(skill, fmt.Sprintf("skills[%d]", i))) {
+					return
+				}
+			}
+			summary, err := s.saveRoutine(owner, name, request.Skills)
+			if !writeAPIError(w, err) {
+				writeJSON(w, summary)
+			}
+		case http.MethodDelete:
+			if !writeAPIError(w, s.routines.Delete(owner, name)) {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}
+	case len(segments) == 3 && segments[2] == "notes":
+		if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+			return
+		}
+		name := segments[1]
+		if r.Method == http.MethodGet {
+			routine, err := s.routines.Get(owner, name)
+			if !writeAPIError(w, err) {
+				writeJSON(w, routine.Notes)
+			}
+			return
+		}
+		var request routineNoteRequest
+		if writeAPIError(w, decodeAPIBody(r, &request)) {
+			return
+		}
+		notes, err := s.addRoutineNote(owner, name, user.Username, request)
+		if !writeAPIError(w, err) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			writeJSON(w, notes)
+		}
+	default:
+		writeAPIProblem(w, 404, "no such resource")
+	}
+}
+
+// --- API Helpers ---
+
+// decodeAPIBody decodes a JSON request body into v, rejecting other content
+// types, unknown fields, trailing data and bodies over maxAPIBodyBytes.
+func decodeAPIBody(r *http.Request, v interface{}) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return &requestError{status: 415, err: errors.New("request body must be application/json")}
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxAPIBodyBytes))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(v)
+	if err == nil && decoder.Decode(&json.RawMessage{}) != io.EOF {
+		err = errors.New("unexpected data after JSON value")
+	}
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &tooLarge):
+		return &requestError{status: 413, err: fmt.Errorf("request body over %d bytes", maxAPIBodyBytes)}
+	case errors.Is(err, io.EOF):
+		return badRequest(errors.New("empty request body"))
+	default:
+		return badRequest(fmt.Errorf("invalid JSON body: %w", err))
+	}
+}
+
+// checkAPISkill rejects skills the page endpoints would quietly accept:
+// unknown shapes or positions, negative values and extra twist phases.
+func checkAPISkill(skill skills.TrampolineSkill, field string) error {
+	switch {
+	case skill.Rotation < 0:
+		return badRequest(fmt.Errorf("%s: rotation must not be negative", field))
+	case skill.Shape < skills.Straight || skill.Shape > skills.Straddle:
+		return badRequest(fmt.Errorf("%s: shape must be Straight, Tuck, Pike or Straddle", field))
+	case skill.TakeoffPosition < skills.Feet || skill.TakeoffPosition > skills.Seat:
+		return badRequest(fmt.Errorf("%s: takeoff_position must be Feet, Front, Back or Seat", field))
+	case len(skill.TwistDistribution) > skills.CalculatePhases(skill.Rotation):
+		return badRequest(fmt.Errorf("%s: rotation %d has %d twist phases, got %d", field, skill.Rotation, skills.CalculatePhases(skill.Rotation), len(skill.TwistDistribution)))
+	}
+	for _, twist := range skill.TwistDistribution {
+		if twist < 0 {
+			return badRequest(fmt.Errorf("%s: twists must not be negative", field))
+		}
+	}
+	return nil
+}
+
+// allowMethods answers 405 unless the request uses one of methods.
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeAPIProblem(w, 405, r.Method+" not allowed")
+	return false
+}
+
+// requireSegments answers 404 unless the path has exactly n segments.
+func requireSegments(w http.ResponseWriter, segments []string, n int) bool {
+	if len(segments) != n {
+		writeAPIProblem(w, 404, "no such resource")
+		return false
+	}
+	return true
+}
+
+// writeAPIError answers err as a Problem and reports whether there was one.
+// Errors the client did not cause are logged and hidden behind a 500.
+func writeAPIError(w http.ResponseWriter, err error) bool {
+	var reqErr *requestError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &reqErr):
+		writeAPIProblem(w, reqErr.status, reqErr.Error())
+	case errors.Is(err, storage.ErrNotFound):
+		writeAPIProblem(w, 404, err.Error())
+	default:
+		log.Printf("Error handling API request: %v", err)
+		writeAPIProblem(w, 500, "")
+	}
+	return true
+}
+
+func writeAPIProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail})
+	if err != nil {
+		log.Printf("Error encoding API problem: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+)
+
+// assets holds the templates and the front-end files, including the
+// vendored Bulma, htmx and Alpine.js, so the binary serves the whole UI
+// without the source tree or network access. static/js/alpine.min.js is
+// dist/cdn.min.js of the alpinejs package, version 3.10.3.
+//
+//go:embed templates static
+var assets embed.FS
+
+// assetFS returns dir on disk, if set, or the embedded directory name.
+func assetFS(dir, name string) (fs.FS, error) {
+	if dir != "" {
+		return os.DirFS(dir), nil
+	}
+	return fs.Sub(assets, name)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+// APIBatchItem is one skill of a batch: the skill's fields, as in
+// APISkillRequest, or a FIG notation with the takeoff position, direction
+// and seat landing it does not carry. A notation cannot be combined with
+// rotation, twist_distribution or shape.
+type APIBatchItem struct {
+	Notation string `json:"notation"`
+	skills.TrampolineSkill
+}
+
+// APIBatchResult is the result for the batch item at Index: the calculated
+// skill or why it could not be calculated.
+type APIBatchResult struct {
+	Index int              `json:"index"`
+	Skill *CalculatedSkill `json:"skill,omitempty"`
+	Error string           `json:"error,omitempty"`
+}
+
+// ndjsonTypes are the media types of newline-delimited JSON bodies.
+var ndjsonTypes = map[string]bool{"application/x-ndjson": true, "application/ndjson": true, "application/jsonl": true}
+
+// handleAPISkillBatch calculates many skills at once, for tariff table
+// imports and the optimiser:
+//
+//	POST /api/v1/skills/batch?rules=
+//
+// The body is a JSON array of APIBatchItem, or one item per line as
+// application/x-ndjson, and the answer is an APIBatchResult per item, in
+// order, in the same format. A bad item gets an error in its result and the
+// other items are still calculated; only a body that cannot be split into
+// items fails the whole batch.
+func (s *Server) handleAPISkillBatch(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.lookupTariffRules(r.URL.Query().Get("rules"))
+	if err != nil {
+		writeAPIError(w, badRequest(err))
+		return
+	}
+	items, ndjson, err := readBatchItems(r)
+	if writeAPIError(w, err) {
+		return
+	}
+
+	results := make([]APIBatchResult, len(items))
+	for i, item := range items {
+		results[i] = APIBatchResult{Index: i}
+		skill, err := decodeBatchItem(item)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		routine.NormalizeTwists(&skill)
+		skill.Name = routine.SkillName(skill)
+		calculated := newCalculatedSkill(&skill, rules)
+		results[i].Skill = &calculated
+	}
+
+	if !ndjson {
+		writeJSON(w, results)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		if err := encoder.Encode(result); err != nil {
+			log.Printf("Error encoding batch result: %v", err)
+			return
+		}
+	}
+}
+
+// readBatchItems splits the body into its raw items, without decoding them,
+// so one bad item does not fail the others. ndjson reports whether the body
+// was newline-delimited.
+func readBatchItems(r *http.Request) (items []json.RawMessage, ndjson bool, err error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	ndjson = ndjsonTypes[mediaType]
+	if !ndjson && mediaType != "application/json" {
+		return nil, false, &requestError{status: 415, err: errors.New("request body must be a JSON array or application/x-ndjson")}
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxAPIBodyBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, ndjson, &requestError{status: 413, err: fmt.Errorf("request body over %d bytes", maxAPIBodyBytes)}
+	} else if err != nil {
+		return nil, ndjson, badRequest(err)
+	}
+
+	if ndjson {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(nil, maxAPIBodyBytes)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				items = append(items, json.RawMessage(bytes.Clone(line)))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, ndjson, badRequest(err)
+		}
+		return items, ndjson, nil
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		return nil, ndjson, badRequest(errors.New("body must be a JSON array of skills"))
+	}
+	if err := json.Unmarshal(body, &items); err != nil {
+		return nil, ndjson, badRequest(fmt.Errorf("invalid JSON body: %w", err))
+	}
+	return items, ndjson, nil
+}
+
+// decodeBatchItem decodes and checks one item as strictly as the single
+// skill endpoints.
+func decodeBatchItem(raw json.RawMessage) (skills.TrampolineSkill, error) {
+	if !bytes.HasPrefix(raw, []byte("{")) {
+		return skills.TrampolineSkill{}, errors.New("invalid skill: must be a JSON object")
+	}
+	var item APIBatchItem
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&item); err != nil {
+		return skills.TrampolineSkill{}, fmt.Errorf("invalid skill: %w", err)
+	}
+	skill := item.TrampolineSkill
+	if item.Notation != "" {
+		// The notation carries the shape, and Straight is the zero Shape,
+		// so look for the fields themselves rather than their values
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return skills.TrampolineSkill{}, fmt.Errorf("invalid skill: %w", err)
+		}
+		for _, field := range []string{"rotation", "twist_distribution", "shape"} {
+			if _, given := fields[field]; given {
+				return skills.TrampolineSkill{}, errors.New("give either notation or rotation, twist_distribution and shape, not both")
+			}
+		}
+		parsed, err := skills.ParseFIGNotation(item.Notation)
+		if err != nil {
+			return skills.TrampolineSkill{}, err
+		}
+		parsed.TakeoffPosition = skill.TakeoffPosition
+		parsed.Backward = skill.Backward
+		parsed.SeatLanding = skill.SeatLanding
+		skill = parsed
+	}
+	skill.Tariff, skill.LandingPosStr = 0, ""
+	if err := checkAPISkill(skill, "skill"); err != nil {
+		return skills.TrampolineSkill{}, errors.Unwrap(err)
+	}
+	return skill, nil
+}
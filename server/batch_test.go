@@ -0,0 +1,46 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"tariffCalculator/skills"
+)
+
+func TestDecodeBatchItem(t *testing.T) {
+	tests := []struct {
+		item     string
+		rotation int
+		shape    skills.Shape
+		backward bool
+		wantErr  bool
+	}{
+		{`{"notation":"(4 0 o)","backward":true}`, 4, skills.Tuck, true, false},
+		{`{"notation":"(8 - 1 <)","takeoff_position":"Feet"}`, 8, skills.Pike, false, false},
+		{`{"rotation":4,"twist_distribution":[2],"shape":"Straight"}`, 4, skills.Straight, false, false},
+		{`{"notation":"(4 0 o)","shape":"Straight"}`, 0, 0, false, true},
+		{`{"notation":"(4 0 o)","shape":"Pike"}`, 0, 0, false, true},
+		{`{"notation":"(4 0 o)","rotation":0}`, 0, 0, false, true},
+		{`{"notation":"(4 0 o)","twist_distribution":[]}`, 0, 0, false, true},
+		{`{"notation":"(4 x o)"}`, 0, 0, false, true},
+		{`{"notation":"(4 0 o)","colour":"red"}`, 0, 0, false, true},
+		{`[4]`, 0, 0, false, true},
+	}
+	for _, test := range tests {
+		skill, err := decodeBatchItem(json.RawMessage(test.item))
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("decodeBatchItem(%s) = %s, want an error", test.item, skill.FIGNotation())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("decodeBatchItem(%s) error: %v", test.item, err)
+			continue
+		}
+		if skill.Rotation != test.rotation || skill.Shape != test.shape || skill.Backward != test.backward {
+			t.Errorf("decodeBatchItem(%s) = rotation %d, shape %v, backward %v; want %d, %v, %v",
+				test.item, skill.Rotation, skill.Shape, skill.Backward, test.rotation, test.shape, test.backward)
+		}
+	}
+}
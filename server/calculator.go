@@ -0,0 +1,891 @@
+package server
+
+import (
+	"bytes" // Required for body reading/replacement
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io" // Required for body reading
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tariffCalculator/categories"
+	"tariffCalculator/dmt"
+	"tariffCalculator/routine"
+	"tariffCalculator/skills" // Ensure this path is correct
+	"tariffCalculator/tumbling"
+)
+
+// --- Structs for Template Data ---
+
+type CommonSkillEntry struct {
+	Key    string
+	Name   string
+	Tariff float64
+}
+
+type SkillFormData struct {
+	Skill         skills.TrampolineSkill
+	CommonSkills  []CommonSkillEntry // Keep this for the main form fragment
+	Index         int
+	EnabledPhases int
+	CurrentTwists []int  // Note: This is for FORM display, might still be 4 elements
+	SortBy        string // Add SortBy for initial form load state
+}
+
+// Added struct for the options template
+type CommonSkillsOptionsData struct {
+	CommonSkills  []CommonSkillEntry
+	SelectedValue string // The key of the currently selected skill (if any)
+}
+
+// IndexPageData is passed to the calculator page and other pages with a code of points selector.
+type IndexPageData struct {
+	RuleSets       []TariffRulesOption
+	DefaultRules   string
+	Profiles       []TariffRulesOption
+	DefaultProfile string
+	Account        *AccountInfo    // nil when nobody is signed in
+	Shared         *SharedRoutine  // Routine from a /r/{code} link, loaded into the calculator
+	Disciplines    map[string]bool // Disciplines the server has pages for, for the navigation bar
+}
+
+// newIndexPageData lists the rule sets and profiles for the page's selects
+// and the signed-in user, if any, for the navigation bar.
+func (s *Server) newIndexPageData(r *http.Request) IndexPageData {
+	data := IndexPageData{DefaultRules: s.config.Rules, DefaultProfile: categories.DefaultProfileID, Disciplines: s.disciplines}
+	if user, ok := currentUser(r); ok {
+		data.Account = s.newAccountInfo(user)
+	}
+	for _, rules := range skills.TariffRuleSets() {
+		data.RuleSets = append(data.RuleSets, TariffRulesOption{ID: rules.ID(), Name: rules.Name()})
+	}
+	for _, profile := range categories.Profiles() {
+		data.Profiles = append(data.Profiles, TariffRulesOption{ID: profile.ID, Name: profile.Name})
+	}
+	return data
+}
+
+type TariffRulesOption struct {
+	ID   string
+	Name string
+}
+
+// --- Template Setup ---
+
+func convertIntSliceToStringSlice(intSlice []int) []string {
+	stringSlice := make([]string, len(intSlice))
+	for i, v := range intSlice {
+		stringSlice[i] = strconv.Itoa(v)
+	}
+	return stringSlice
+}
+
+func seq(start, end int) []int {
+	if start > end {
+		return []int{}
+	}
+	s := make([]int, end-start+1)
+	for i := range s {
+		s[i] = start + i
+	}
+	return s
+}
+
+var funcMap = template.FuncMap{
+	"add":      func(a, b int) int { return a + b },
+	"sub":      func(a, b int) int { return a - b },
+	"multiply": func(a, b int) int { return a * b },
+	"json": func(v interface{}) (template.JS, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return template.JS(b), nil
+	},
+	"ternary": func(condition bool, trueVal, falseVal interface{}) interface{} {
+		if condition {
+			return trueVal
+		}
+		return falseVal
+	},
+	"abs": func(x int) int {
+		if x < 0 {
+			return -x
+		}
+		return x
+	},
+	"default": func(def, val interface{}) interface{} {
+		sVal := fmt.Sprintf("%v", val)
+		if sVal == "" || sVal == "0" || sVal == "<nil>" || sVal == "[]" {
+			return def
+		}
+		return val
+	},
+	"safeHTMLAttr": func(s string) template.HTMLAttr { return template.HTMLAttr(s) },
+	"skillKey": func(s skills.TrampolineSkill) string {
+		// Ensure TwistDistribution is not nil before joining
+		twists := []int{0} // Default if nil
+		if s.TwistDistribution != nil {
+			twists = s.TwistDistribution
+		}
+		return fmt.Sprintf("R%d_T%s_S%s_B%t_SL%t_TP%s", s.Rotation, strings.Join(convertIntSliceToStringSlice(twists), "_"), s.Shape.String(), s.Backward, s.SeatLanding, s.TakeoffPosition.String())
+	},
+	"join": func(sep string, a []int) string { return strings.Join(convertIntSliceToStringSlice(a), sep) },
+	"seq":  seq,
+}
+
+// --- Route Handlers ---
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	err := s.tmpl.ExecuteTemplate(w, "base.html", s.newIndexPageData(r))
+	if err != nil {
+		log.Printf("Error executing base template: %v", err)
+		http.Error(w, "Internal Server Error", 500)
+	}
+}
+
+// handlePage renders a page from s.pages.
+func (s *Server) handlePage(w http.ResponseWriter, page string, data interface{}) {
+	s.renderPage(w, page, "base.html", data)
+}
+
+// renderPage executes a page in layout, a top-level template that includes
+// the page's "content".
+func (s *Server) renderPage(w http.ResponseWriter, page, layout string, data interface{}) {
+	t, ok := s.pages[page]
+	if !ok {
+		log.Printf("Error: page template %s not loaded", page)
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	err := t.ExecuteTemplate(w, layout, data)
+	if err != nil {
+		log.Printf("Error executing %s page template: %v", page, err)
+		http.Error(w, "Internal Server Error", 500)
+	}
+}
+
+func (s *Server) handleDMTPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "dmt", s.newIndexPageData(r))
+}
+
+// handleDMTValidatePass receives a DMT pass as JSON and returns its validation JSON.
+func (s *Server) handleDMTValidatePass(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var pass dmt.Pass
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&pass)
+	if err != nil {
+		log.Printf("Error decoding DMT pass JSON: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	for _, skill := range []*skills.TrampolineSkill{&pass.First, &pass.Dismount} {
+		routine.NormalizeTwists(skill)
+		skill.Name = routine.SkillName(*skill)
+	}
+
+	validationData := pass.Validate()
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(validationData)
+	if encodeErr != nil {
+		log.Printf("Error encoding DMT validation JSON: %v", encodeErr)
+	}
+}
+
+func (s *Server) handleTumblingPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "tumbling", s.newIndexPageData(r))
+}
+
+// handleTumblingValidatePass receives a JSON array of tumbling elements and returns the pass validation JSON.
+func (s *Server) handleTumblingValidatePass(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var elements []tumbling.Element
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&elements)
+	if err != nil {
+		log.Printf("Error decoding tumbling pass JSON: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	for i := range elements {
+		skill := elements[i].Skill()
+		routine.NormalizeTwists(&skill)
+		elements[i].TwistDistribution = skill.TwistDistribution
+	}
+
+	validationData := tumbling.ValidatePass(elements)
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(validationData)
+	if encodeErr != nil {
+		log.Printf("Error encoding tumbling validation JSON: %v", encodeErr)
+	}
+}
+
+// getSortedCommonSkills retrieves and sorts common skills based on parameters.
+// sortBy: "tariff-desc" (default), "tariff-asc", "alpha-asc", "alpha-desc"
+func getSortedCommonSkills(sortBy string, rules skills.TariffRules) []CommonSkillEntry {
+	catalogue := skills.Catalogue()
+	skillList := make([]CommonSkillEntry, 0, len(catalogue))
+	for key, s := range catalogue {
+		tempSkill := s
+		tempSkill.SetTariff(rules)
+		skillList = append(skillList, CommonSkillEntry{Key: key, Name: tempSkill.Name, Tariff: tempSkill.Tariff})
+	}
+
+	// Sorting logic
+	sort.Slice(skillList, func(i, j int) bool {
+		switch sortBy {
+		case "tariff-asc":
+			if skillList[i].Tariff != skillList[j].Tariff {
+				return skillList[i].Tariff < skillList[j].Tariff
+			}
+			return skillList[i].Name < skillList[j].Name // Secondary sort by name
+		case "alpha-asc":
+			if skillList[i].Name != skillList[j].Name {
+				return skillList[i].Name < skillList[j].Name
+			}
+			return skillList[i].Tariff > skillList[j].Tariff // Secondary sort by tariff desc
+		case "alpha-desc":
+			if skillList[i].Name != skillList[j].Name {
+				return skillList[i].Name > skillList[j].Name
+			}
+			return skillList[i].Tariff > skillList[j].Tariff // Secondary sort by tariff desc
+		case "tariff-desc":
+			fallthrough // Default case
+		default:
+			if skillList[i].Tariff != skillList[j].Tariff {
+				return skillList[i].Tariff > skillList[j].Tariff
+			}
+			return skillList[i].Name < skillList[j].Name // Secondary sort by name
+		}
+	})
+	return skillList
+}
+
+// prepareSkillFormData calculates derived data needed for form templates.
+func prepareSkillFormData(skillData skills.TrampolineSkill, index int, sortBy string) SkillFormData {
+	enabledPhases := skills.CalculatePhases(skillData.Rotation)
+
+	currentTwists := make([]int, 4)
+	if skillData.TwistDistribution != nil {
+		copyCount := len(skillData.TwistDistribution)
+		if copyCount > 4 {
+			copyCount = 4
+		}
+		copy(currentTwists, skillData.TwistDistribution[:copyCount])
+	}
+
+	return SkillFormData{
+		Skill:         skillData,
+		CommonSkills:  nil, // Will be populated later if needed
+		Index:         index,
+		EnabledPhases: enabledPhases,
+		CurrentTwists: currentTwists,
+		SortBy:        sortBy, // Store current sort order
+	}
+}
+
+// handleSkillFormFragment serves the *entire* form fragment.
+func (s *Server) handleSkillFormFragment(w http.ResponseWriter, r *http.Request) {
+	skillKey := r.URL.Query().Get("commonSkillKey")
+	editIndexStr := r.URL.Query().Get("editIndex")
+	sortBy := r.URL.Query().Get("sortBy") // Get sort preference
+	if sortBy == "" {
+		sortBy = "tariff-desc" // Default sort
+	}
+
+	rules, err := s.tariffRulesFromRequest(r)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	editIndex, err := strconv.Atoi(editIndexStr)
+	if err != nil || editIndex < 0 {
+		editIndex = -1
+	}
+
+	var skillData skills.TrampolineSkill
+	if skillKey != "" {
+		if commonSkill, exists := skills.GetCommonSkill(skillKey); exists {
+			skillData = commonSkill
+			skillData.SetTariff(rules)
+		} else {
+			skillData = skills.TrampolineSkill{Rotation: 4, TakeoffPosition: skills.Feet, Shape: skills.Straight, TwistDistribution: []int{0}}
+			skillData.SetTariff(rules)
+		}
+	} else if editIndex == -1 {
+		skillData = skills.TrampolineSkill{Rotation: 4, TakeoffPosition: skills.Feet, Shape: skills.Straight, TwistDistribution: []int{0}}
+		skillData.SetTariff(rules)
+	} else {
+		// When loading for edit, we need the actual skill data, not a default
+		routine, parseErr := parseRoutineFromRequest(r, rules)
+		if parseErr == nil && editIndex < len(routine) {
+			skillData = routine[editIndex]
+			// Recalculate tariff just in case
+			skillData.SetTariff(rules)
+		} else {
+			log.Printf("Error parsing routine or index out of bounds for edit in handleSkillFormFragment: %v", parseErr)
+			// Fallback to default if parsing fails or index is bad
+			skillData = skills.TrampolineSkill{Rotation: 4, TakeoffPosition: skills.Feet, Shape: skills.Straight, TwistDistribution: []int{0}}
+			skillData.SetTariff(rules)
+		}
+	}
+
+	formData := prepareSkillFormData(skillData, editIndex, sortBy)
+	formData.CommonSkills = getSortedCommonSkills(sortBy, rules) // Get sorted skills
+
+	if s.tmpl.Lookup("skill-form-fragment.html") == nil {
+		log.Println("Error: skill-form-fragment.html template not loaded")
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	err = s.tmpl.ExecuteTemplate(w, "skill-form-fragment.html", formData)
+	if err != nil {
+		log.Printf("Error executing skill-form-fragment template: %v", err)
+	}
+}
+
+// handleSkillInputsFragment serves ONLY the inputs part of the form.
+func (s *Server) handleSkillInputsFragment(w http.ResponseWriter, r *http.Request) {
+	skillKey := r.URL.Query().Get("commonSkillKey")
+	editIndexStr := r.URL.Query().Get("editIndex")
+	sortBy := r.URL.Query().Get("sortBy") // Get sort preference (though not directly used here)
+	if sortBy == "" {
+		sortBy = "tariff-desc" // Default sort
+	}
+
+	editIndex, err := strconv.Atoi(editIndexStr)
+	if err != nil || editIndex < 0 {
+		editIndex = -1
+	}
+
+	var skillData skills.TrampolineSkill
+	if skillKey != "" {
+		if commonSkill, exists := skills.GetCommonSkill(skillKey); exists {
+			skillData = commonSkill
+		} else {
+			skillData = skills.TrampolineSkill{Rotation: 4, TakeoffPosition: skills.Feet, Shape: skills.Straight, TwistDistribution: []int{0}}
+		}
+	} else {
+		// If no common skill, load default or existing skill for edit
+		if editIndex != -1 {
+			routine, parseErr := parseRoutineFromRequest(r, nil)
+			if parseErr == nil && editIndex < len(routine) {
+				skillData = routine[editIndex]
+			} else {
+				log.Printf("Error parsing routine or index out of bounds for edit in handleSkillInputsFragment: %v", parseErr)
+				skillData = skills.TrampolineSkill{Rotation: 4, TakeoffPosition: skills.Feet, Shape: skills.Straight, TwistDistribution: []int{0}}
+			}
+		} else {
+			skillData = skills.TrampolineSkill{Rotation: 4, TakeoffPosition: skills.Feet, Shape: skills.Straight, TwistDistribution: []int{0}}
+		}
+	}
+
+	// We still need to prepare the full form data to pass to the fragment template
+	formData := prepareSkillFormData(skillData, editIndex, sortBy)
+
+	if s.tmpl.Lookup("skill-inputs-fragment.html") == nil {
+		log.Println("Error: skill-inputs-fragment.html template not loaded")
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	err = s.tmpl.ExecuteTemplate(w, "skill-inputs-fragment.html", formData)
+	if err != nil {
+		log.Printf("Error executing skill-inputs-fragment template: %v", err)
+	}
+}
+
+// handleEditSkillFormData loads data for editing and renders the *entire* form fragment.
+func (s *Server) handleEditSkillFormData(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/edit-skill-form-data/"), "/")
+	if len(parts) < 1 {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+	indexStr := parts[0]
+	index, err := strconv.Atoi(indexStr)
+	if err != nil || index < 0 {
+		http.Error(w, "Bad Request: Invalid index", 400)
+		return
+	}
+
+	// Get sort preference if provided (e.g., from hidden input or previous state)
+	sortBy := r.URL.Query().Get("sortBy")
+	if sortBy == "" {
+		sortBy = "tariff-desc" // Default
+	}
+
+	rules, err := s.tariffRulesFromRequest(r)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	routine, err := parseRoutineFromRequest(r, rules)
+	if err != nil {
+		log.Printf("Error parsing routine for edit: %v", err)
+		http.Error(w, "Bad Request: Could not parse routine data", 400)
+		return
+	}
+
+	if index >= len(routine) {
+		log.Printf("Error: Edit index %d out of bounds for routine length %d", index, len(routine))
+		http.Error(w, "Bad Request: Index out of bounds", 400)
+		return
+	}
+
+	skillToEdit := routine[index]
+	formData := prepareSkillFormData(skillToEdit, index, sortBy)
+	formData.CommonSkills = getSortedCommonSkills(sortBy, rules) // Get sorted skills
+
+	if s.tmpl.Lookup("skill-form-fragment.html") == nil {
+		log.Println("Error: skill-form-fragment.html template not loaded")
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	err = s.tmpl.ExecuteTemplate(w, "skill-form-fragment.html", formData)
+	if err != nil {
+		log.Printf("Error executing edit form fragment template: %v", err)
+	}
+}
+
+// --- Utility Functions (parseSkillFromForm, ShapeFromString, etc.) ---
+
+// parseSkillFromForm parses skill data from a submitted form.
+func parseSkillFromForm(r *http.Request) (skills.TrampolineSkill, error) {
+	skill := skills.TrampolineSkill{}
+	skill.Name = r.FormValue("name")
+	rotationVal := r.FormValue("rotation")
+	rotation, _ := strconv.Atoi(rotationVal)
+	skill.Rotation = rotation
+	skill.TakeoffPosition = skills.BodyPositionFromString(r.FormValue("takeoff_position"))
+	skill.Shape = skills.ShapeFromString(r.FormValue("shape")) // Use function from skills package
+	skill.Backward = r.FormValue("backward") == "on"
+	skill.SeatLanding = r.FormValue("seat_landing") == "on"
+
+	numPhases := skills.CalculatePhases(skill.Rotation)
+
+	twistValues := r.Form["twist_distribution[]"]
+	skill.TwistDistribution = make([]int, 0, numPhases)
+	for i := 0; i < numPhases; i++ {
+		twist := 0
+		if i < len(twistValues) {
+			parsedTwist, err := strconv.Atoi(twistValues[i])
+			if err == nil {
+				twist = parsedTwist
+			} else {
+				log.Printf("Warning: Invalid twist value '%s' at index %d, using 0.", twistValues[i], i)
+			}
+		} else {
+			log.Printf("Warning: Missing twist value for phase %d, using 0.", i+1)
+		}
+		skill.TwistDistribution = append(skill.TwistDistribution, twist)
+	}
+
+	return skill, nil
+}
+
+// handleCalculateSingleSkill parses JSON, calculates, finds name, returns JSON.
+func (s *Server) handleCalculateSingleSkill(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var requestPayload struct {
+		Name              string `json:"name"`
+		Rotation          int    `json:"rotation"`
+		TwistDistribution []int  `json:"twist_distribution"`
+		TakeoffPosition   string `json:"takeoff_position"`
+		Shape             string `json:"shape"`
+		Backward          bool   `json:"backward"`
+		SeatLanding       bool   `json:"seat_landing"`
+		Rules             string `json:"rules"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&requestPayload)
+	if err != nil {
+		log.Printf("Error decoding JSON payload for calculation: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	rules, err := s.lookupTariffRules(requestPayload.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	skill := skills.TrampolineSkill{
+		Name:              requestPayload.Name, // Start with name from request
+		Rotation:          requestPayload.Rotation,
+		TwistDistribution: requestPayload.TwistDistribution,
+		TakeoffPosition:   skills.BodyPositionFromString(requestPayload.TakeoffPosition),
+		Shape:             skills.ShapeFromString(requestPayload.Shape), // Use function from skills package
+		Backward:          requestPayload.Backward,
+		SeatLanding:       requestPayload.SeatLanding,
+	}
+
+	// Adjust twist distribution slice length based on rotation
+	expectedPhases := skills.CalculatePhases(skill.Rotation)
+	if len(skill.TwistDistribution) > expectedPhases {
+		skill.TwistDistribution = skill.TwistDistribution[:expectedPhases]
+	} else {
+		for len(skill.TwistDistribution) < expectedPhases {
+			skill.TwistDistribution = append(skill.TwistDistribution, 0)
+		}
+	}
+
+	skill.Name = routine.SkillName(skill)
+	writeCalculatedSkill(w, &skill, rules)
+}
+
+// handleCalculateNotation parses a FIG notation string, calculates, returns JSON.
+// FIG notation carries no direction or takeoff, so those can be given alongside it.
+func (s *Server) handleCalculateNotation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var requestPayload struct {
+		Notation        string `json:"notation"`
+		TakeoffPosition string `json:"takeoff_position"`
+		Backward        bool   `json:"backward"`
+		SeatLanding     bool   `json:"seat_landing"`
+		Rules           string `json:"rules"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&requestPayload)
+	if err != nil {
+		log.Printf("Error decoding JSON payload for notation: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	rules, err := s.lookupTariffRules(requestPayload.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	skill, err := skills.ParseFIGNotation(requestPayload.Notation)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	if requestPayload.TakeoffPosition != "" {
+		skill.TakeoffPosition = skills.BodyPositionFromString(requestPayload.TakeoffPosition)
+	}
+	skill.Backward = requestPayload.Backward
+	skill.SeatLanding = requestPayload.SeatLanding
+
+	skill.Name = routine.SkillName(skill)
+	writeCalculatedSkill(w, &skill, rules)
+}
+
+// CalculatedSkill is the JSON response of the skill calculation endpoints.
+type CalculatedSkill struct {
+	Name              string                 `json:"name"`
+	Rotation          int                    `json:"rotation"`
+	TwistDistribution []int                  `json:"twist_distribution"`
+	TakeoffPosition   string                 `json:"takeoff_position"`
+	Shape             string                 `json:"shape"`
+	Backward          bool                   `json:"backward"`
+	SeatLanding       bool                   `json:"seat_landing"`
+	Tariff            float64                `json:"tariff"`
+	LandingPosition   string                 `json:"landing_position"`
+	FIGNotation       string                 `json:"fig_notation"`
+	Breakdown         skills.TariffBreakdown `json:"breakdown"`
+}
+
+// newCalculatedSkill sets the tariff on skill and returns it as a CalculatedSkill.
+func newCalculatedSkill(skill *skills.TrampolineSkill, rules skills.TariffRules) CalculatedSkill {
+	skill.SetTariff(rules)
+	landingPos := skill.LandingPosition()
+
+	return CalculatedSkill{
+		Name:              skill.Name, // Use the final name (either found common name or "Custom Skill")
+		Rotation:          skill.Rotation,
+		TwistDistribution: skill.TwistDistribution, // Use the adjusted slice
+		TakeoffPosition:   skill.TakeoffPosition.String(),
+		Shape:             skill.Shape.String(),
+		Backward:          skill.Backward,
+		SeatLanding:       skill.SeatLanding,
+		Tariff:            skill.Tariff,
+		LandingPosition:   landingPos.String(),
+		FIGNotation:       skill.FIGNotation(),
+		Breakdown:         skill.TariffBreakdown(rules),
+	}
+}
+
+// writeCalculatedSkill sets the tariff on skill and writes it as a CalculatedSkill.
+func writeCalculatedSkill(w http.ResponseWriter, skill *skills.TrampolineSkill, rules skills.TariffRules) {
+	response := newCalculatedSkill(skill, rules)
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(response)
+	if encodeErr != nil {
+		log.Printf("Error encoding JSON response for calculation: %v", encodeErr)
+	}
+}
+
+// handleEvaluateSkillFragment parses Form Data, calculates, renders HTML fragment.
+func (s *Server) handleEvaluateSkillFragment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		log.Printf("Error parsing form for eval: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	skill, err := parseSkillFromForm(r)
+	if err != nil {
+		log.Printf("Error processing form data for eval: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	rules, err := s.tariffRulesFromRequest(r)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	skill.Name = routine.SkillName(skill)
+
+	skill.SetTariff(rules)
+	landingPos := skill.LandingPosition()
+	figNotation := skill.FIGNotation()
+
+	// Ensure skill data for fragment has correct twist length before marshalling
+	expectedPhases := skills.CalculatePhases(skill.Rotation)
+	if len(skill.TwistDistribution) > expectedPhases {
+		skill.TwistDistribution = skill.TwistDistribution[:expectedPhases]
+	}
+
+	skillJson, jsonErr := json.Marshal(skill)
+	if jsonErr != nil {
+		log.Printf("Error marshalling skill to JSON for eval fragment: %v", jsonErr)
+		skillJson = []byte("{}")
+	}
+
+	data := map[string]interface{}{
+		"Skill":          skill,
+		"LandingPosStr":  landingPos.String(),
+		"LandingIsValid": landingPos != skills.Invalid,
+		"SkillDataJSON":  string(skillJson),
+		"FIGNotation":    figNotation,
+		"Breakdown":      skill.TariffBreakdown(rules)}
+
+	if s.tmpl.Lookup("evaluation-fragment.html") == nil {
+		log.Println("Error: evaluation-fragment.html template not loaded")
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	err = s.tmpl.ExecuteTemplate(w, "evaluation-fragment.html", data)
+	if err != nil {
+		log.Printf("Error executing eval fragment: %v", err)
+	}
+}
+
+// handleValidateRoutineClientState receives routine JSON and returns validation JSON.
+func (s *Server) handleValidateRoutineClientState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	rules, err := s.tariffRulesFromRequest(r)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	skillList, err := parseRoutineFromRequest(r, rules)
+	if err != nil {
+		log.Printf("Error parsing routine for validation: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	profile, err := lookupProfile(r.FormValue("profile"))
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	routineToValidate := routine.Routine(skillList)
+	routineToValidate.Prepare()
+
+	opts := routine.Options{Rules: rules, Profile: profile}
+	validationData := routineToValidate.Validate(opts)
+	validationData.Suggestions = routineToValidate.Suggest(validationData, opts)
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(validationData)
+	if encodeErr != nil {
+		log.Printf("Error encoding validation JSON: %v", encodeErr)
+	}
+}
+
+// handleCommonSkillsOptions serves *only* the <option> tags for the dropdown.
+func (s *Server) handleCommonSkillsOptions(w http.ResponseWriter, r *http.Request) {
+	sortBy := r.URL.Query().Get("sortBy")
+	selectedValue := r.URL.Query().Get("selectedValue") // Get the current value if needed
+	if sortBy == "" {
+		sortBy = "tariff-desc" // Default sort
+	}
+
+	rules, err := s.tariffRulesFromRequest(r)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	sortedSkills := getSortedCommonSkills(sortBy, rules)
+
+	data := CommonSkillsOptionsData{
+		CommonSkills:  sortedSkills,
+		SelectedValue: selectedValue,
+	}
+
+	// Execute the specific template for options
+	if s.tmpl.Lookup("common-skills-options.html") == nil {
+		log.Println("Error: common-skills-options.html template not loaded")
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	err = s.tmpl.ExecuteTemplate(w, "common-skills-options.html", data)
+	if err != nil {
+		log.Printf("Error executing common-skills-options template: %v", err)
+	}
+}
+
+// --- Helper Functions ---
+
+// tariffRulesFromRequest returns the rule set selected by the "rules" form or
+// query value, or the default rule set if none was selected.
+func (s *Server) tariffRulesFromRequest(r *http.Request) (skills.TariffRules, error) {
+	return s.lookupTariffRules(r.FormValue("rules"))
+}
+
+func (s *Server) lookupTariffRules(id string) (skills.TariffRules, error) {
+	if id == "" {
+		return s.rules, nil
+	}
+	rules, exists := skills.GetTariffRules(id)
+	if !exists {
+		return nil, fmt.Errorf("unknown tariff rule set %q", id)
+	}
+	return rules, nil
+}
+
+func lookupProfile(id string) (*categories.Profile, error) {
+	if id == "" {
+		return categories.DefaultProfile(), nil
+	}
+	profile, exists := categories.GetProfile(id)
+	if !exists {
+		return nil, fmt.Errorf("unknown category profile %q", id)
+	}
+	return profile, nil
+}
+
+// checkRoutineLength rejects routines too long to validate, see
+// routine.MaxLength.
+func checkRoutineLength(skillList []skills.TrampolineSkill) error {
+	return routine.Routine(skillList).CheckLength()
+}
+
+// parseRoutineFromRequest parses JSON routine data from form/query/body.
+// Tariffs are calculated with rules (nil for the default rule set).
+func parseRoutineFromRequest(r *http.Request, rules skills.TariffRules) ([]skills.TrampolineSkill, error) {
+	var routine []skills.TrampolineSkill
+	var rawData []byte
+	var err error
+
+	// Try form value first
+	if errForm := r.ParseForm(); errForm == nil {
+		routineDataStr := r.FormValue("routineData")
+		if routineDataStr != "" {
+			rawData = []byte(routineDataStr)
+		}
+	} else if r.ContentLength > 0 {
+		log.Printf("Warning: Error parsing form in parseRoutineFromRequest: %v", errForm)
+	}
+
+	// Fallback to query parameter
+	if len(rawData) == 0 {
+		routineDataStr := r.URL.Query().Get("routineData")
+		if routineDataStr != "" {
+			rawData = []byte(routineDataStr)
+		}
+	}
+
+	// Fallback to request body
+	if len(rawData) == 0 && r.Body != nil && r.ContentLength > 0 && (r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch) {
+		bodyBytes, readErr := io.ReadAll(r.Body)
+		if readErr == nil {
+			rawData = bodyBytes
+		} else if readErr != io.EOF {
+			log.Printf("Error reading request body: %v", readErr)
+		}
+		r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes)) // Replace body
+		if r.Form != nil {                                // Reset ContentLength if ParseForm was called
+			r.ContentLength = int64(len(bodyBytes))
+		}
+	}
+
+	if len(rawData) == 0 {
+		return []skills.TrampolineSkill{}, nil // No data found
+	}
+
+	// Attempt to unmarshal
+	err = json.Unmarshal(rawData, &routine)
+	if err != nil {
+		decodedStr, decErr := url.QueryUnescape(string(rawData))
+		if decErr == nil {
+			err = json.Unmarshal([]byte(decodedStr), &routine)
+		}
+		if err != nil {
+			log.Printf("ERROR: Failed to decode routine JSON: %v. Raw data: %s", err, string(rawData))
+			return nil, fmt.Errorf("failed to decode routine JSON: %w", err)
+		}
+	}
+
+	if err := checkRoutineLength(routine); err != nil {
+		return nil, err
+	}
+
+	// Post-processing: Set tariff, landing string, and correct twist length
+	for i := range routine {
+		routine[i].SetTariff(rules)
+		routine[i].LandingPosStr = routine[i].LandingPosition().String()
+		expectedPhases := skills.CalculatePhases(routine[i].Rotation)
+		if len(routine[i].TwistDistribution) > expectedPhases {
+			routine[i].TwistDistribution = routine[i].TwistDistribution[:expectedPhases]
+		} else {
+			for len(routine[i].TwistDistribution) < expectedPhases {
+				routine[i].TwistDistribution = append(routine[i].TwistDistribution, 0)
+			}
+		}
+		// Don't update name here, let validation handle it if needed
+	}
+	return routine, nil
+}
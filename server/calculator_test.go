@@ -0,0 +1,56 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"tariffCalculator/accounts"
+	"tariffCalculator/routine"
+)
+
+// TestRoutineLengthLimit sends a routine one skill over routine.MaxLength
+// to every handler that stores or prints routines.
+func TestRoutineLengthLimit(t *testing.T) {
+	s, err := NewServer(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.accounts.Register("organiser1", "password123", accounts.Organiser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := s.accounts.NewSession(user.Username)
+	long, err := json.Marshal(slices.Repeat(repertoire("backSomersault"), routine.MaxLength+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"PUT", "/api/v1/routines/long", fmt.Sprintf(`{"skills":%s}`, long), 400},
+		{"POST", "/competition-card", fmt.Sprintf(`{"routines":{"R1":%s}}`, long), 400},
+		{"POST", "/competitions", `{"name":"Open","date":"2026-10-17"}`, 200},
+		{"POST", "/competitions/1/categories", `{"name":"U15"}`, 200},
+		{"POST", "/competitions/1/categories/1/athletes", fmt.Sprintf(`{"name":"A","routines":{"R1":%s}}`, long), 400},
+		{"PUT", "/competitions/1/categories/1/athletes/1/routines/R1", string(long), 404},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		r.Header.Set("Content-Type", "application/json")
+		r.AddCookie(&http.Cookie{Name: sessionCookieName, Value: session})
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != test.want {
+			t.Errorf("%s %s = %d, want %d: %s", test.method, test.path, w.Code, test.want, w.Body)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"tariffCalculator/categories"
+	"tariffCalculator/pdf"
+	"tariffCalculator/qr"
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+// CompetitionCardRequest is the header and declared routines of a competition
+// card. Routines are keyed by routine key ("R1", "R2", "F"); sections without
+// a routine are printed blank to be filled in by hand.
+type CompetitionCardRequest struct {
+	Athlete     string                              `json:"athlete"`
+	Club        string                              `json:"club"`
+	Competition string                              `json:"competition"`
+	Category    string                              `json:"category"`
+	Date        string                              `json:"date"`
+	Rules       string                              `json:"rules"`
+	Profile     string                              `json:"profile"`
+	Routines    map[string][]skills.TrampolineSkill `json:"routines"`
+}
+
+type competitionCard struct {
+	Athlete     string
+	Club        string
+	Competition string
+	Category    string
+	Date        string
+	Rules       string // Name of the code of points
+	Routines    []cardRoutine
+}
+
+type cardRoutine struct {
+	Title      string
+	Validation *routine.Result // nil for a blank section
+	QRCode     *qr.Code        // Share link to the routine; nil for a blank section
+}
+
+// cardRoutineKeys are the sections of a card, in print order.
+var cardRoutineKeys = []string{"R1", "R2", "F"}
+
+var cardRoutineTitles = map[string]string{
+	"R1": "Routine 1",
+	"R2": "Routine 2",
+	"F":  "Final",
+}
+
+func cardRoutineTitle(key string) string {
+	if title, ok := cardRoutineTitles[key]; ok {
+		return title
+	}
+	return key
+}
+
+// newCompetitionCard validates the routines for the given keys with the rules
+// and profile, and encodes each routine's share link on the request's host.
+func (s *Server) newCompetitionCard(r *http.Request, header CompetitionCardRequest, keys []string, rules skills.TariffRules, profile *categories.Profile) competitionCard {
+	card := competitionCard{
+		Athlete:     header.Athlete,
+		Club:        header.Club,
+		Competition: header.Competition,
+		Category:    header.Category,
+		Date:        header.Date,
+		Rules:       rules.Name(),
+	}
+	for _, key := range keys {
+		section := cardRoutine{Title: cardRoutineTitle(key)}
+		if skillList, declared := header.Routines[key]; declared && len(skillList) > 0 {
+			routine.Routine(skillList).Prepare()
+			validation := routine.Routine(skillList).Validate(routine.Options{Rules: rules, Profile: profile})
+			section.Validation = &validation
+			if code, err := skills.EncodeRoutine(skillList); err == nil {
+				section.QRCode, err = s.routineQRCode(r, code)
+				if err != nil {
+					log.Printf("Error encoding card QR code: %v", err)
+				}
+			}
+		}
+		card.Routines = append(card.Routines, section)
+	}
+	return card
+}
+
+// --- Card Layout ---
+
+const (
+	cardMargin    = 36.0
+	cardRowHeight = 14.0
+	cardQRSide    = 120.0
+)
+
+// writeCompetitionCardPDF draws the card on A4: the header fields, then a
+// table of skill, FIG notation and tariff for each routine with its total
+// tariff and a QR code of its share link, then signature lines. Tables have
+// routineLength rows, and a last row counting any skills beyond them. The
+// usual three routines fit on one page.
+func writeCompetitionCardPDF(w io.Writer, card competitionCard) error {
+	doc := pdf.New(pdf.A4Width, pdf.A4Height)
+	doc.Title = "Competition Card"
+	if card.Athlete != "" {
+		doc.Title += " - " + card.Athlete
+	}
+	page := doc.AddPage()
+	left, right := cardMargin, pdf.A4Width-cardMargin
+	width := right - left
+
+	page.Text(left, 58, pdf.HelveticaBold, 18, "COMPETITION CARD")
+	page.TextRight(right, 58, pdf.Helvetica, 9, "Code of Points: "+card.Rules)
+
+	y := 72.0
+	field := func(x, w float64, label, value string) {
+		page.Rect(x, y, w, 28)
+		page.SetTextGrey(0.4)
+		page.Text(x+4, y+9, pdf.Helvetica, 7, label)
+		page.SetTextGrey(0)
+		page.Text(x+4, y+23, pdf.Helvetica, 11, pdf.Truncate(pdf.Helvetica, 11, value, w-8))
+	}
+	page.SetLineWidth(0.75)
+	field(left, width*0.6, "ATHLETE", card.Athlete)
+	field(left+width*0.6, width*0.4, "CLUB", card.Club)
+	y += 28
+	field(left, width*0.45, "COMPETITION", card.Competition)
+	field(left+width*0.45, width*0.35, "CATEGORY", card.Category)
+	field(left+width*0.8, width*0.2, "DATE", card.Date)
+	y += 28 + 14
+
+	for _, section := range card.Routines {
+		var validation routine.Result
+		if section.Validation != nil {
+			validation = *section.Validation
+		}
+		rows := routineLength
+		hidden := len(validation.Skills) - routineLength
+		if hidden > 0 {
+			rows++
+		}
+
+		// Rounds with more routines than fit continue on another page
+		sectionHeight := 18 + cardRowHeight*float64(rows+2) + 14
+		if y+sectionHeight > pdf.A4Height-cardMargin-40 {
+			page = doc.AddPage()
+			page.SetLineWidth(0.75)
+			y = cardMargin
+		}
+		page.FillRect(left, y, width, 18, 0.85)
+		page.Rect(left, y, width, 18)
+		page.Text(left+6, y+13, pdf.HelveticaBold, 11, section.Title)
+		y += 18
+
+		// Table columns: number, skill name, FIG notation, tariff. The QR
+		// code, if any, sits to the right of the table
+		tableRight := right
+		if section.QRCode != nil {
+			tableRight = right - cardQRSide - 8
+			drawCardQRCode(page, section.QRCode, right-cardQRSide, y+4)
+			page.SetTextGrey(0.4)
+			page.TextCentre(right-cardQRSide/2, y+cardQRSide+14, pdf.Helvetica, 7, "Scan to check in the calculator")
+			page.SetTextGrey(0)
+		}
+		tableWidth := tableRight - left
+		numberX, nameX, notationX, tariffRight := left+4, left+28, left+tableWidth*0.55, tableRight-6
+
+		page.Text(numberX, y+10, pdf.HelveticaBold, 8, "No.")
+		page.Text(nameX, y+10, pdf.HelveticaBold, 8, "Skill")
+		page.Text(notationX, y+10, pdf.HelveticaBold, 8, "FIG Notation")
+		page.TextRight(tariffRight, y+10, pdf.HelveticaBold, 8, "Tariff")
+		y += cardRowHeight
+
+		for i := 0; i < routineLength; i++ {
+			page.Line(left, y, tableRight, y)
+			page.Text(numberX, y+10, pdf.Helvetica, 9, fmt.Sprintf("%d", i+1))
+			if i < len(validation.Skills) {
+				skill := validation.Skills[i]
+				page.Text(nameX, y+10, pdf.Helvetica, 9, pdf.Truncate(pdf.Helvetica, 9, skill.Name, notationX-nameX-6))
+				page.Text(notationX, y+10, pdf.Helvetica, 9, skill.FIGNotation)
+				page.TextRight(tariffRight, y+10, pdf.Helvetica, 9, fmt.Sprintf("%.1f", skill.Tariff))
+			}
+			y += cardRowHeight
+		}
+		if hidden > 0 {
+			more := fmt.Sprintf("%d more skills not shown", hidden)
+			if hidden == 1 {
+				more = "1 more skill not shown"
+			}
+			page.Line(left, y, tableRight, y)
+			page.SetTextGrey(0.4)
+			page.Text(nameX, y+10, pdf.Helvetica, 9, more)
+			page.SetTextGrey(0)
+			y += cardRowHeight
+		}
+		page.Line(left, y, tableRight, y)
+		page.Rect(left, y-cardRowHeight*float64(rows+1), tableWidth, cardRowHeight*float64(rows+2))
+		page.TextRight(tariffRight-50, y+10, pdf.HelveticaBold, 9, "Total Tariff")
+		if section.Validation != nil {
+			page.TextRight(tariffRight, y+10, pdf.HelveticaBold, 10, fmt.Sprintf("%.1f", validation.TotalTariff))
+		}
+		y += cardRowHeight
+
+		if problems := cardRoutineProblems(validation); problems != "" {
+			page.Text(left+4, y+9, pdf.Helvetica, 7, pdf.Truncate(pdf.Helvetica, 7, "Check: "+problems, width-8))
+		}
+		y += 14
+	}
+
+	y += 24
+	page.Line(left, y, left+width*0.42, y)
+	page.Line(right-width*0.42, y, right, y)
+	page.Text(left, y+10, pdf.Helvetica, 8, "Coach signature")
+	page.Text(right-width*0.42, y+10, pdf.Helvetica, 8, "Athlete signature")
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// drawCardQRCode draws a QR code cardQRSide points square, including its
+// quiet zone, with the top-left corner at (x, y).
+func drawCardQRCode(page *pdf.Page, code *qr.Code, x, y float64) {
+	// Whole hundredths of a point keep adjacent modules from leaving seams
+	module := math.Floor(cardQRSide/float64(code.Size+2*qr.QuietZone)*100) / 100
+	x += module * qr.QuietZone
+	y += module * qr.QuietZone
+	code.Runs(func(col, row, length int) {
+		page.FillRect(x+float64(col)*module, y+float64(row)*module, float64(length)*module, module, 0)
+	})
+}
+
+// cardRoutineProblems summarises the validation messages printed under a
+// routine so mistakes are caught before the card is handed in.
+func cardRoutineProblems(validation routine.Result) string {
+	var problems []string
+	for i, message := range validation.Messages {
+		if message != "" {
+			problems = append(problems, fmt.Sprintf("%d: %s", i+1, message))
+		}
+	}
+	problems = append(problems, validation.ProfileViolations...)
+	return strings.Join(problems, "; ")
+}
+
+// --- Competition Card Handlers ---
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// writeCompetitionCard sends the card as an inline PDF download.
+func writeCompetitionCard(w http.ResponseWriter, card competitionCard) {
+	var buf bytes.Buffer
+	err := writeCompetitionCardPDF(&buf, card)
+	if err != nil {
+		log.Printf("Error writing competition card PDF: %v", err)
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	filename := "competition-card"
+	if athlete := unsafeFilenameChars.ReplaceAllString(card.Athlete, "-"); strings.Trim(athlete, "-") != "" {
+		filename += "-" + strings.Trim(athlete, "-")
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename+".pdf"))
+	_, err = buf.WriteTo(w)
+	if err != nil {
+		log.Printf("Error sending competition card PDF: %v", err)
+	}
+}
+
+func (s *Server) handleCardPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "card", s.newIndexPageData(r))
+}
+
+// handleCompetitionCard receives a CompetitionCardRequest as JSON and returns
+// the competition card PDF.
+func (s *Server) handleCompetitionCard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var request CompetitionCardRequest
+	if !decodeJSONBody(w, r, &request) {
+		return
+	}
+	rules, err := s.lookupTariffRules(request.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	profile, err := lookupProfile(request.Profile)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	for key, skillList := range request.Routines {
+		if _, ok := cardRoutineTitles[key]; !ok {
+			http.Error(w, fmt.Sprintf("Bad Request: unknown routine %q, expected R1, R2 or F", key), 400)
+			return
+		}
+		if err := checkRoutineLength(skillList); err != nil {
+			http.Error(w, fmt.Sprintf("Bad Request: routine %s: %v", key, err), 400)
+			return
+		}
+	}
+	writeCompetitionCard(w, s.newCompetitionCard(r, request, cardRoutineKeys, rules, profile))
+}
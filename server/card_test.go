@@ -0,0 +1,58 @@
+package server
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"regexp"
+	"testing"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+// cardText returns the content streams of a card PDF, one per page.
+func cardText(t *testing.T, card competitionCard) []string {
+	var out bytes.Buffer
+	if err := writeCompetitionCardPDF(&out, card); err != nil {
+		t.Fatal(err)
+	}
+	var pages []string
+	for _, stream := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllSubmatch(out.Bytes(), -1) {
+		reader, err := zlib.NewReader(bytes.NewReader(stream[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, string(content))
+	}
+	return pages
+}
+
+func TestCompetitionCardHiddenSkills(t *testing.T) {
+	tests := []struct {
+		extra int
+		want  string
+	}{
+		{0, ""},
+		{1, "(1 more skill not shown)"},
+		{2, "(2 more skills not shown)"},
+	}
+	for _, test := range tests {
+		skillList := append(scoringRoutine(), repertoire("backSomersault", "backSomersault")[:test.extra]...)
+		routine.Routine(skillList).Prepare()
+		validation := routine.Routine(skillList).Validate(routine.Options{Rules: skills.DefaultTariffRules()})
+		card := competitionCard{Rules: "Test", Routines: []cardRoutine{{Title: "Routine 1", Validation: &validation}, {Title: "Routine 2"}, {Title: "Final"}}}
+		pages := cardText(t, card)
+		if len(pages) != 1 {
+			t.Errorf("%d extra skills: %d pages, want 1", test.extra, len(pages))
+		}
+		more := regexp.MustCompile(`\(\d+ more skills? not shown\)`).FindString(pages[0])
+		if more != test.want {
+			t.Errorf("%d extra skills: %q, want %q", test.extra, more, test.want)
+		}
+	}
+}
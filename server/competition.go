@@ -0,0 +1,656 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tariffCalculator/accounts"
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+// --- Competition Model ---
+
+// Competition is a local competition with its categories. Rules is the tariff
+// rule set used for every routine.
+type Competition struct {
+	ID         int                    `json:"id"`
+	Name       string                 `json:"name"`
+	Date       string                 `json:"date"`
+	Rules      string                 `json:"rules"`
+	Owner      string                 `json:"owner"` // Organiser who created it, the only account that can change it
+	Categories []*CompetitionCategory `json:"categories"`
+	nextID     int
+}
+
+// CompetitionCategory groups the athletes that are ranked together, e.g.
+// "Women 13-14". Profile is the category profile their routines are
+// validated against.
+type CompetitionCategory struct {
+	ID            int                   `json:"id"`
+	Name          string                `json:"name"`
+	Profile       string                `json:"profile"`
+	FinalSize     int                   `json:"finalSize"` // Athletes qualifying for the final, 0 for no final
+	Qualification RoundConfig           `json:"qualification"`
+	Final         RoundConfig           `json:"final"`
+	Athletes      []*CompetitionAthlete `json:"athletes"`
+}
+
+// RoundConfig says which routines make up a round and how athletes are
+// ranked in it.
+type RoundConfig struct {
+	Routines  []string `json:"routines"`  // Routine keys scored in the round, e.g. "R1", "R2"
+	Aggregate string   `json:"aggregate"` // "sum" of the routines' scores or the "best" one
+	TieBreaks []string `json:"tieBreaks"` // Applied in order to equal totals, see tieBreakNames
+}
+
+// CompetitionAthlete is an athlete with their declared routines, keyed by
+// routine key, and the scores they have received.
+type CompetitionAthlete struct {
+	ID          int                                 `json:"id"`
+	Name        string                              `json:"name"`
+	Club        string                              `json:"club"`
+	Flight      int                                 `json:"flight"`
+	StartNumber int                                 `json:"startNumber"`
+	Routines    map[string][]skills.TrampolineSkill `json:"routines"`
+	Validation  map[string]routine.Result           `json:"validation"`
+	Scores      map[string]ScoreData                `json:"scores"`
+}
+
+const (
+	qualificationRound = "qualification"
+	finalRound         = "final"
+)
+
+// Default rounds: two qualification routines added together, then one final
+// routine. Ties are broken on execution first.
+var (
+	defaultQualification = RoundConfig{Routines: []string{"R1", "R2"}, Aggregate: "sum", TieBreaks: []string{"execution", "time-of-flight", "horizontal-displacement"}}
+	defaultFinal         = RoundConfig{Routines: []string{"F"}, Aggregate: "sum", TieBreaks: []string{"execution", "difficulty"}}
+)
+
+// tieBreakNames maps a tie-break to the value compared, higher first.
+var tieBreakNames = map[string]func(result *RankedAthlete) float64{
+	"execution":               func(result *RankedAthlete) float64 { return result.Execution },
+	"difficulty":              func(result *RankedAthlete) float64 { return result.Difficulty },
+	"time-of-flight":          func(result *RankedAthlete) float64 { return result.TimeOfFlight },
+	"horizontal-displacement": func(result *RankedAthlete) float64 { return result.HorizontalDisplacement },
+	"best-routine":            func(result *RankedAthlete) float64 { return result.BestRoutine },
+	"fewest-penalties":        func(result *RankedAthlete) float64 { return -result.Penalty },
+}
+
+// RankedAthlete is one line of a round's results. Component totals add up the
+// routines that make the athlete's total.
+type RankedAthlete struct {
+	Rank                   int                  `json:"rank"`
+	AthleteID              int                  `json:"athleteId"`
+	Name                   string               `json:"name"`
+	Club                   string               `json:"club"`
+	Total                  float64              `json:"total"`
+	Difficulty             float64              `json:"difficulty"`
+	Execution              float64              `json:"execution"`
+	HorizontalDisplacement float64              `json:"horizontalDisplacement"`
+	TimeOfFlight           float64              `json:"timeOfFlight"`
+	Penalty                float64              `json:"penalty"`
+	BestRoutine            float64              `json:"bestRoutine"`
+	Scores                 map[string]ScoreData `json:"scores"`
+	Complete               bool                 `json:"complete"` // Every routine of the round has been scored
+}
+
+type StartListFlight struct {
+	Flight   int                   `json:"flight"`
+	Athletes []*CompetitionAthlete `json:"athletes"`
+}
+
+// competitionStore keeps every competition in memory only, so competitions,
+// entries and scores are lost when the server restarts. All access goes
+// through mu.
+type competitionStore struct {
+	mu           sync.Mutex
+	competitions map[int]*Competition
+	nextID       int
+}
+
+func (competition *Competition) category(id int) *CompetitionCategory {
+	for _, category := range competition.Categories {
+		if category.ID == id {
+			return category
+		}
+	}
+	return nil
+}
+
+func (category *CompetitionCategory) athlete(id int) *CompetitionAthlete {
+	for _, athlete := range category.Athletes {
+		if athlete.ID == id {
+			return athlete
+		}
+	}
+	return nil
+}
+
+func (category *CompetitionCategory) round(name string) (RoundConfig, error) {
+	switch name {
+	case "", qualificationRound:
+		return category.Qualification, nil
+	case finalRound:
+		if category.FinalSize == 0 {
+			return RoundConfig{}, fmt.Errorf("category %q has no final", category.Name)
+		}
+		return category.Final, nil
+	}
+	return RoundConfig{}, fmt.Errorf("unknown round %q", name)
+}
+
+// validateRoutines validates every declared routine with the competition's
+// rules and the category's profile. Routines longer than routine.MaxLength
+// are an error.
+func (s *Server) validateRoutines(athlete *CompetitionAthlete, competition *Competition, category *CompetitionCategory) error {
+	for key, skillList := range athlete.Routines {
+		if err := checkRoutineLength(skillList); err != nil {
+			return fmt.Errorf("routine %s: %w", key, err)
+		}
+	}
+	rules, err := s.lookupTariffRules(competition.Rules)
+	if err != nil {
+		return err
+	}
+	profile, err := lookupProfile(category.Profile)
+	if err != nil {
+		return err
+	}
+	athlete.Validation = map[string]routine.Result{}
+	for key, skillList := range athlete.Routines {
+		routine.Routine(skillList).Prepare()
+		athlete.Validation[key] = routine.Routine(skillList).Validate(routine.Options{Rules: rules, Profile: profile})
+	}
+	return nil
+}
+
+func (config *RoundConfig) applyDefaults(defaults RoundConfig) error {
+	if len(config.Routines) == 0 {
+		config.Routines = defaults.Routines
+	}
+	if config.Aggregate == "" {
+		config.Aggregate = defaults.Aggregate
+	}
+	if config.Aggregate != "sum" && config.Aggregate != "best" {
+		return fmt.Errorf("unknown aggregate %q", config.Aggregate)
+	}
+	if config.TieBreaks == nil {
+		config.TieBreaks = defaults.TieBreaks
+	}
+	for _, tieBreak := range config.TieBreaks {
+		if _, ok := tieBreakNames[tieBreak]; !ok {
+			return fmt.Errorf("unknown tie-break %q", tieBreak)
+		}
+	}
+	return nil
+}
+
+// roundResults ranks the athletes on the round's routines. Athletes with
+// equal totals are separated by the round's tie-breaks in order and share a
+// rank if every tie-break is equal.
+func roundResults(athletes []*CompetitionAthlete, config RoundConfig) []*RankedAthlete {
+	results := make([]*RankedAthlete, 0, len(athletes))
+	for _, athlete := range athletes {
+		result := &RankedAthlete{AthleteID: athlete.ID, Name: athlete.Name, Club: athlete.Club, Scores: map[string]ScoreData{}, Complete: true}
+		var counted []ScoreData
+		for _, key := range config.Routines {
+			score, scored := athlete.Scores[key]
+			if !scored {
+				result.Complete = false
+				continue
+			}
+			result.Scores[key] = score
+			counted = append(counted, score)
+			result.BestRoutine = max(result.BestRoutine, score.Final)
+		}
+		if config.Aggregate == "best" && len(counted) > 0 {
+			best := slices.MaxFunc(counted, func(a, b ScoreData) int { return compareFloat(a.Final, b.Final) })
+			counted = []ScoreData{best}
+		}
+		for _, score := range counted {
+			result.Total += score.Final
+			result.Difficulty += score.Difficulty
+			result.Execution += score.Execution
+			result.HorizontalDisplacement += score.HorizontalDisplacement
+			result.TimeOfFlight += score.TimeOfFlight
+			result.Penalty += score.Penalty
+		}
+		result.Total = roundScore(result.Total)
+		results = append(results, result)
+	}
+
+	compare := func(a, b *RankedAthlete) int {
+		if c := compareFloat(b.Total, a.Total); c != 0 {
+			return c
+		}
+		for _, tieBreak := range config.TieBreaks {
+			value := tieBreakNames[tieBreak]
+			if c := compareFloat(roundScore(value(b)), roundScore(value(a))); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	slices.SortStableFunc(results, compare)
+	for i, result := range results {
+		result.Rank = i + 1
+		if i > 0 && compare(results[i-1], result) == 0 {
+			result.Rank = results[i-1].Rank
+		}
+	}
+	return results
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// finalists returns the athletes qualifying for the final, best qualifier
+// first.
+func (category *CompetitionCategory) finalists() []*CompetitionAthlete {
+	qualification := roundResults(category.Athletes, category.Qualification)
+	var finalists []*CompetitionAthlete
+	for _, result := range qualification {
+		if len(finalists) == category.FinalSize {
+			break
+		}
+		if !result.Complete {
+			continue // Withdrawn before finishing qualification
+		}
+		finalists = append(finalists, category.athlete(result.AthleteID))
+	}
+	return finalists
+}
+
+// results ranks the athletes competing in the round: everyone in
+// qualification, the finalists in the final.
+func (category *CompetitionCategory) results(round string) ([]*RankedAthlete, error) {
+	config, err := category.round(round)
+	if err != nil {
+		return nil, err
+	}
+	athletes := category.Athletes
+	if round == finalRound {
+		athletes = category.finalists()
+	}
+	return roundResults(athletes, config), nil
+}
+
+// startList orders the round's athletes for competing: qualification by
+// flight and start number, the final in reverse order of qualification.
+func (category *CompetitionCategory) startList(round string) []StartListFlight {
+	if round == finalRound {
+		finalists := category.finalists()
+		slices.Reverse(finalists)
+		return []StartListFlight{{Flight: 1, Athletes: finalists}}
+	}
+	flights := map[int][]*CompetitionAthlete{}
+	for _, athlete := range category.Athletes {
+		flights[athlete.Flight] = append(flights[athlete.Flight], athlete)
+	}
+	list := []StartListFlight{}
+	for flight, athletes := range flights {
+		sort.SliceStable(athletes, func(i, j int) bool { return athletes[i].StartNumber < athletes[j].StartNumber })
+		list = append(list, StartListFlight{Flight: flight, Athletes: athletes})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Flight < list[j].Flight })
+	return list
+}
+
+// --- Competition Handlers ---
+
+func (s *Server) handleCompetitionsPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "competitions", s.newIndexPageData(r))
+}
+
+// requireCompetitionStaff returns the signed-in coach or organiser, or
+// answers 401 or 403 and reports false. Only the scoreboard is public.
+func requireCompetitionStaff(w http.ResponseWriter, r *http.Request) (accounts.User, bool) {
+	user, ok := requireUser(w, r)
+	if ok && user.Role != accounts.Coach && user.Role != accounts.Organiser {
+		http.Error(w, "Forbidden: competitions are run by coaches and organisers", 403)
+		return user, false
+	}
+	return user, ok
+}
+
+// handleCompetitions dispatches the competition API by path:
+//
+//	GET  /competitions
+//	POST /competitions
+//	GET  /competitions/{id}
+//	POST /competitions/{id}/categories
+//	POST /competitions/{id}/categories/{categoryID}/athletes
+//	PUT  /competitions/{id}/categories/{categoryID}/athletes/{athleteID}/routines/{key}
+//	GET  /competitions/{id}/categories/{categoryID}/athletes/{athleteID}/card
+//	GET  /competitions/{id}/categories/{categoryID}/start-list?round=qualification|final
+//	POST /competitions/{id}/categories/{categoryID}/scores
+//	GET  /competitions/{id}/categories/{categoryID}/results?round=qualification|final
+//
+// Every route needs a coach or organiser account. Only organisers create
+// competitions, and only a competition's owner changes it.
+func (s *Server) handleCompetitions(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireCompetitionStaff(w, r)
+	if !ok {
+		return
+	}
+	write := r.Method != http.MethodGet
+	if write && user.Role != accounts.Organiser {
+		http.Error(w, "Forbidden: competitions are changed by organisers", 403)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/competitions"), "/"), "/")
+	if parts[0] == "" {
+		parts = nil
+	}
+	// Even parts are IDs and odd parts name the collection, except the
+	// routine key at the end of a routines path
+	var ids []int
+	var names []string
+	for i, part := range parts {
+		if i%2 == 1 {
+			names = append(names, part)
+			continue
+		}
+		if i == 6 {
+			break
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil {
+			http.Error(w, "Not Found", 404)
+			return
+		}
+		ids = append(ids, id)
+	}
+	route := strings.Join(names, "/")
+
+	s.competitions.mu.Lock()
+	defer s.competitions.mu.Unlock()
+
+	if len(parts) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			list := make([]*Competition, 0, len(s.competitions.competitions))
+			for _, competition := range s.competitions.competitions {
+				list = append(list, competition)
+			}
+			sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+			writeJSON(w, list)
+		case http.MethodPost:
+			s.createCompetition(w, r, user)
+		default:
+			http.Error(w, "Method Not Allowed", 405)
+		}
+		return
+	}
+
+	competition, exists := s.competitions.competitions[ids[0]]
+	if !exists {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+	if write && competition.Owner != user.Username {
+		http.Error(w, "Forbidden: only the competition's organiser can change it", 403)
+		return
+	}
+	var category *CompetitionCategory
+	if len(ids) > 1 {
+		if category = competition.category(ids[1]); category == nil {
+			http.Error(w, "Not Found", 404)
+			return
+		}
+	}
+
+	switch {
+	case route == "" && r.Method == http.MethodGet:
+		writeJSON(w, competition)
+	case route == "categories" && len(ids) == 1 && r.Method == http.MethodPost:
+		s.createCategory(w, r, competition)
+	case route == "categories/athletes" && len(ids) == 2 && r.Method == http.MethodPost:
+		s.createAthlete(w, r, competition, category)
+	case route == "categories/athletes/routines" && len(ids) == 3 && len(parts) == 7 && r.Method == http.MethodPut:
+		athlete := category.athlete(ids[2])
+		if athlete == nil {
+			http.Error(w, "Not Found", 404)
+			return
+		}
+		s.declareRoutine(w, r, competition, category, athlete, parts[6])
+	case route == "categories/athletes/card" && len(ids) == 3 && r.Method == http.MethodGet:
+		athlete := category.athlete(ids[2])
+		if athlete == nil {
+			http.Error(w, "Not Found", 404)
+			return
+		}
+		s.writeAthleteCard(w, r, competition, category, athlete)
+	case route == "categories/start-list" && len(ids) == 2 && r.Method == http.MethodGet:
+		if _, err := category.round(r.URL.Query().Get("round")); err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), 400)
+			return
+		}
+		writeJSON(w, category.startList(r.URL.Query().Get("round")))
+	case route == "categories/scores" && len(ids) == 2 && r.Method == http.MethodPost:
+		s.recordScore(w, r, competition, category)
+	case route == "categories/results" && len(ids) == 2 && r.Method == http.MethodGet:
+		results, err := category.results(r.URL.Query().Get("round"))
+		if err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), 400)
+			return
+		}
+		writeJSON(w, results)
+	default:
+		http.Error(w, "Not Found", 404)
+	}
+}
+
+// decodeJSONBody decodes the request body into v, rejecting unknown fields,
+// and writes a 400 response if it fails.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		log.Printf("Error decoding JSON payload for %s: %v", r.URL.Path, err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(v)
+	if encodeErr != nil {
+		log.Printf("Error encoding JSON response: %v", encodeErr)
+	}
+}
+
+func (s *Server) createCompetition(w http.ResponseWriter, r *http.Request, user accounts.User) {
+	var competition Competition
+	if !decodeJSONBody(w, r, &competition) {
+		return
+	}
+	if competition.Name == "" {
+		http.Error(w, "Bad Request: missing name", 400)
+		return
+	}
+	if _, err := s.lookupTariffRules(competition.Rules); err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	if competition.Rules == "" {
+		competition.Rules = s.config.Rules
+	}
+	s.competitions.nextID++
+	competition.ID = s.competitions.nextID
+	competition.Owner = user.Username
+	competition.Categories = []*CompetitionCategory{}
+	s.competitions.competitions[competition.ID] = &competition
+	writeJSON(w, &competition)
+}
+
+func (s *Server) createCategory(w http.ResponseWriter, r *http.Request, competition *Competition) {
+	var category CompetitionCategory
+	if !decodeJSONBody(w, r, &category) {
+		return
+	}
+	if category.Name == "" {
+		http.Error(w, "Bad Request: missing name", 400)
+		return
+	}
+	profile, err := lookupProfile(category.Profile)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	category.Profile = profile.ID
+	if err := category.Qualification.applyDefaults(defaultQualification); err != nil {
+		http.Error(w, "Bad Request: qualification: "+err.Error(), 400)
+		return
+	}
+	if err := category.Final.applyDefaults(defaultFinal); err != nil {
+		http.Error(w, "Bad Request: final: "+err.Error(), 400)
+		return
+	}
+	competition.nextID++
+	category.ID = competition.nextID
+	category.Athletes = []*CompetitionAthlete{}
+	competition.Categories = append(competition.Categories, &category)
+	writeJSON(w, &category)
+}
+
+func (s *Server) createAthlete(w http.ResponseWriter, r *http.Request, competition *Competition, category *CompetitionCategory) {
+	var athlete CompetitionAthlete
+	if !decodeJSONBody(w, r, &athlete) {
+		return
+	}
+	if athlete.Name == "" {
+		http.Error(w, "Bad Request: missing name", 400)
+		return
+	}
+	if athlete.Routines == nil {
+		athlete.Routines = map[string][]skills.TrampolineSkill{}
+	}
+	if err := s.validateRoutines(&athlete, competition, category); err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	competition.nextID++
+	athlete.ID = competition.nextID
+	if athlete.StartNumber == 0 {
+		athlete.StartNumber = len(category.Athletes) + 1
+	}
+	athlete.Scores = map[string]ScoreData{}
+	category.Athletes = append(category.Athletes, &athlete)
+	writeJSON(w, &athlete)
+}
+
+// declareRoutine replaces one of an athlete's declared routines. The body is
+// the routine's skills.
+func (s *Server) declareRoutine(w http.ResponseWriter, r *http.Request, competition *Competition, category *CompetitionCategory, athlete *CompetitionAthlete, key string) {
+	var routine []skills.TrampolineSkill
+	if !decodeJSONBody(w, r, &routine) {
+		return
+	}
+	if err := checkRoutineLength(routine); err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	athlete.Routines[key] = routine
+	if err := s.validateRoutines(athlete, competition, category); err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	writeJSON(w, athlete)
+}
+
+// writeAthleteCard prints the competition card for an athlete's declared
+// routines, one section per routine of the category's rounds.
+func (s *Server) writeAthleteCard(w http.ResponseWriter, r *http.Request, competition *Competition, category *CompetitionCategory, athlete *CompetitionAthlete) {
+	rules, err := s.lookupTariffRules(competition.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	profile, err := lookupProfile(category.Profile)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	keys := slices.Clone(category.Qualification.Routines)
+	if category.FinalSize > 0 {
+		keys = append(keys, category.Final.Routines...)
+	}
+	routines := map[string][]skills.TrampolineSkill{}
+	for key, routine := range athlete.Routines {
+		routines[key] = slices.Clone(routine)
+	}
+	header := CompetitionCardRequest{
+		Athlete:     athlete.Name,
+		Club:        athlete.Club,
+		Competition: competition.Name,
+		Category:    category.Name,
+		Date:        competition.Date,
+		Routines:    routines,
+	}
+	writeCompetitionCard(w, s.newCompetitionCard(r, header, keys, rules, profile))
+}
+
+// recordScore scores one routine of an athlete with calculateScore, using the
+// declared routine for difficulty. The body is a ScoreRequest without the
+// routine, plus athleteId and routineKey.
+func (s *Server) recordScore(w http.ResponseWriter, r *http.Request, competition *Competition, category *CompetitionCategory) {
+	var request struct {
+		ScoreRequest
+		AthleteID  int    `json:"athleteId"`
+		RoutineKey string `json:"routineKey"`
+	}
+	if !decodeJSONBody(w, r, &request) {
+		return
+	}
+	athlete := category.athlete(request.AthleteID)
+	if athlete == nil {
+		http.Error(w, "Bad Request: unknown athlete", 400)
+		return
+	}
+	declaredRoutine, declared := athlete.Routines[request.RoutineKey]
+	if !declared {
+		http.Error(w, fmt.Sprintf("Bad Request: %s has not declared routine %q", athlete.Name, request.RoutineKey), 400)
+		return
+	}
+	rules, err := s.lookupTariffRules(competition.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	profile, err := lookupProfile(category.Profile)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	request.Routine = slices.Clone(declaredRoutine)
+	score, err := calculateScore(request.ScoreRequest, routine.Options{Rules: rules, Profile: profile})
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	athlete.Scores[request.RoutineKey] = score
+	s.publishScore(competition, category, athlete, request.RoutineKey, score)
+	writeJSON(w, score)
+}
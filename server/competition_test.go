@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tariffCalculator/accounts"
+)
+
+func TestCompetitionAPIAccounts(t *testing.T) {
+	s, err := NewServer(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Organisers cannot register themselves, so they are added to the
+	// registry directly, as the tariff organiser command does
+	sessions := map[string]string{}
+	for username, role := range map[string]accounts.Role{"athlete1": accounts.Athlete, "coach1": accounts.Coach, "organiser1": accounts.Organiser, "organiser2": accounts.Organiser} {
+		user, err := s.accounts.Register(username, "password123", role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions[username] = s.accounts.NewSession(user.Username)
+	}
+
+	tests := []struct {
+		user   string // Empty when nobody is signed in
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"", "POST", "/account/register", `{"username":"boss","password":"password123","role":"organiser"}`, 403},
+		{"", "GET", "/competitions", "", 401},
+		{"", "POST", "/competitions", `{"name":"Open","date":"2026-10-17"}`, 401},
+		{"athlete1", "POST", "/competitions", `{"name":"Open","date":"2026-10-17"}`, 403},
+		{"coach1", "POST", "/competitions", `{"name":"Open","date":"2026-10-17"}`, 403},
+		{"organiser1", "POST", "/competitions", `{"name":"Open","date":"2026-10-17"}`, 200},
+		{"coach1", "GET", "/competitions/1", "", 200},
+		{"organiser2", "GET", "/competitions/1", "", 200},
+		{"", "POST", "/competitions/1/categories", `{"name":"U15"}`, 401},
+		{"coach1", "POST", "/competitions/1/categories", `{"name":"U15"}`, 403},
+		{"organiser2", "POST", "/competitions/1/categories", `{"name":"U15"}`, 403},
+		{"organiser1", "POST", "/competitions/1/categories", `{"name":"U15"}`, 200},
+		{"", "POST", "/competitions/1/categories/1/scores", `{"athleteId":1,"routineKey":"R1"}`, 401},
+		{"athlete1", "POST", "/competitions/1/categories/1/scores", `{"athleteId":1,"routineKey":"R1"}`, 403},
+		{"coach1", "POST", "/competitions/1/categories/1/scores", `{"athleteId":1,"routineKey":"R1"}`, 403},
+		{"organiser2", "POST", "/competitions/1/categories/1/scores", `{"athleteId":1,"routineKey":"R1"}`, 403},
+		{"athlete1", "PUT", "/competitions/1/categories/1/athletes/1/routines/R1", `[]`, 403},
+		{"organiser2", "POST", "/competitions/1/categories/1/athletes", `{"name":"A"}`, 403},
+		{"", "GET", "/scoreboard/1", "", 200},
+		{"organiser1", "POST", "/competitions/1/categories/1/athletes", `{"name":"A","routines":{"R1":[]}}`, 200},
+		{"organiser1", "POST", "/competitions/1/categories/1/scores", `{"athleteId":2,"routineKey":"R1","interrupted":true,"execution":[[]],"landing":[]}`, 200},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+		if test.user != "" {
+			r.AddCookie(&http.Cookie{Name: sessionCookieName, Value: sessions[test.user]})
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != test.want {
+			t.Errorf("%s %s as %q = %d, want %d: %s", test.method, test.path, test.user, w.Code, test.want, w.Body)
+		}
+	}
+	if _, exists := s.accounts.User("boss"); exists {
+		t.Error("organiser account registered through /account/register")
+	}
+	// Only the owner's score reaches the public scoreboard
+	if s.scoreboard.lastID != 1 {
+		t.Errorf("%d scoreboard events published, want 1", s.scoreboard.lastID)
+	}
+}
+
+func TestFinalists(t *testing.T) {
+	scored := func(finals ...float64) map[string]ScoreData {
+		scores := map[string]ScoreData{}
+		for i, final := range finals {
+			scores[[]string{"R1", "R2"}[i]] = ScoreData{Final: final}
+		}
+		return scores
+	}
+	category := CompetitionCategory{
+		FinalSize:     2,
+		Qualification: defaultQualification,
+		Athletes: []*CompetitionAthlete{
+			{ID: 1, Name: "First", Scores: scored(50, 50)},
+			{ID: 2, Name: "Withdrawn", Scores: scored(55)},
+			{ID: 3, Name: "Second", Scores: scored(25, 25)},
+			{ID: 4, Name: "Third", Scores: scored(20, 20)},
+		},
+	}
+	var names []string
+	for _, athlete := range category.finalists() {
+		names = append(names, athlete.Name)
+	}
+	if got := strings.Join(names, ", "); got != "First, Second" {
+		t.Errorf("finalists = %s, want First, Second", got)
+	}
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+const (
+	routineLength          = 10
+	defaultOptimizerTop    = 5
+	maxOptimizerTop        = 20
+	maxOptimizerRepertoire = 60
+	maxOptimizerNodes      = 5_000_000 // Search budget; results are still returned, marked not exhaustive
+)
+
+// OptimizedRoutine is one routine found by the optimiser with its full
+// validation, so callers see the same messages as for a hand-built routine.
+type OptimizedRoutine struct {
+	Skills      []skills.TrampolineSkill `json:"skills"`
+	TotalTariff float64                  `json:"totalTariff"`
+	Validation  routine.Result           `json:"validation"`
+}
+
+type OptimizeRoutineData struct {
+	Routines   []OptimizedRoutine `json:"routines"`
+	Exhaustive bool               `json:"exhaustive"` // False if the search budget ran out before every routine was considered
+	Messages   []string           `json:"messages"`
+}
+
+// routineOptimizer searches for the highest-tariff routines with a
+// depth-first branch and bound. Tariffs are held in tenths so totals compare
+// exactly.
+type routineOptimizer struct {
+	skills  []skills.TrampolineSkill
+	tenths  []int
+	byValue []int // Skill indices, highest tariff first
+	top     int
+
+	uses  []int // Times each skill is in the path
+	path  []int
+	best  []optimizerResult // Highest total first, at most top entries
+	nodes int
+
+	// Skills with the same takeoff and landing can swap places without
+	// changing the routine's validity or total, so each such group is only
+	// tried in byValue order. lastRank holds the byValue position of the
+	// group's latest skill in the path.
+	lastRank map[[2]skills.BodyPosition]int
+}
+
+type optimizerResult struct {
+	path   []int
+	tenths int
+	key    string // Sorted skill indices; orderings of the same skills count once
+}
+
+// optimizeRoutine returns the top routines of routineLength skills from the
+// repertoire. Routines start from feet, each skill takes off from the
+// previous landing and the last skill lands on feet. A skill may be repeated,
+// e.g. a back drop used as a connector, but as in validation a skill equal
+// under TrampolineSkill.Equal to an earlier one adds no tariff.
+func optimizeRoutine(repertoire []skills.TrampolineSkill, top int, opts routine.Options) OptimizeRoutineData {
+	data := OptimizeRoutineData{Routines: []OptimizedRoutine{}, Messages: []string{}}
+	optimizer := &routineOptimizer{top: top}
+
+	// Keep the higher-tariff version of equal skills, drop skills that can
+	// never be chained and skills the category does not allow
+	for _, skill := range repertoire {
+		skill.SetTariff(opts.Rules)
+		if skill.Validate() != nil || skill.LandingPosition() == skills.Invalid {
+			data.Messages = append(data.Messages, fmt.Sprintf("%s: Skipped (Invalid Skill)", skill.Name))
+			continue
+		}
+		if opts.Profile != nil && len(opts.Profile.CheckSkill(&skill)) > 0 {
+			data.Messages = append(data.Messages, fmt.Sprintf("%s: Skipped (Not Allowed In %s)", skill.Name, opts.Profile.Name))
+			continue
+		}
+		duplicate := slices.IndexFunc(optimizer.skills, func(existing skills.TrampolineSkill) bool { return existing.Equal(&skill) })
+		if duplicate == -1 {
+			optimizer.skills = append(optimizer.skills, skill)
+		} else if skill.Tariff > optimizer.skills[duplicate].Tariff {
+			optimizer.skills[duplicate] = skill
+		}
+	}
+	for i := range optimizer.skills {
+		optimizer.tenths = append(optimizer.tenths, int(math.Round(optimizer.skills[i].Tariff*10)))
+		optimizer.byValue = append(optimizer.byValue, i)
+	}
+	sort.SliceStable(optimizer.byValue, func(i, j int) bool {
+		return optimizer.tenths[optimizer.byValue[i]] > optimizer.tenths[optimizer.byValue[j]]
+	})
+	optimizer.uses = make([]int, len(optimizer.skills))
+	optimizer.lastRank = map[[2]skills.BodyPosition]int{}
+
+	data.Exhaustive = optimizer.search(skills.Feet, 0)
+
+	for _, result := range optimizer.best {
+		skillList := make([]skills.TrampolineSkill, len(result.path))
+		for i, index := range result.path {
+			skillList[i] = optimizer.skills[index]
+		}
+		validation := routine.Routine(skillList).Validate(opts)
+		data.Routines = append(data.Routines, OptimizedRoutine{Skills: skillList, TotalTariff: float64(result.tenths) / 10, Validation: validation})
+	}
+	if len(data.Routines) == 0 {
+		data.Messages = append(data.Messages, fmt.Sprintf("No Valid %d-Skill Routine From This Repertoire", routineLength))
+	}
+	if !data.Exhaustive {
+		data.Messages = append(data.Messages, "Search Limit Reached (Best Routines Found So Far)")
+	}
+	return data
+}
+
+// search extends the current path from the given takeoff position. It
+// returns false once the node budget is spent.
+func (optimizer *routineOptimizer) search(takeoff skills.BodyPosition, tenths int) bool {
+	optimizer.nodes++
+	if optimizer.nodes > maxOptimizerNodes {
+		return false
+	}
+	if len(optimizer.path) == routineLength {
+		if takeoff == skills.Feet {
+			optimizer.record(tenths)
+		}
+		return true
+	}
+	if len(optimizer.best) == optimizer.top && tenths+optimizer.bound() <= optimizer.best[len(optimizer.best)-1].tenths {
+		return true
+	}
+
+	for rank, index := range optimizer.byValue {
+		skill := &optimizer.skills[index]
+		if skill.TakeoffPosition != takeoff {
+			continue
+		}
+		group := [2]skills.BodyPosition{skill.TakeoffPosition, skill.LandingPosition()}
+		previous, grouped := optimizer.lastRank[group]
+		if grouped && rank < previous {
+			continue
+		}
+		value := optimizer.tenths[index]
+		if optimizer.uses[index] > 0 {
+			value = 0 // Repeats count once
+		}
+		optimizer.uses[index]++
+		optimizer.path = append(optimizer.path, index)
+		optimizer.lastRank[group] = rank
+		complete := optimizer.search(group[1], tenths+value)
+		if grouped {
+			optimizer.lastRank[group] = previous
+		} else {
+			delete(optimizer.lastRank, group)
+		}
+		optimizer.path = optimizer.path[:len(optimizer.path)-1]
+		optimizer.uses[index]--
+		if !complete {
+			return false
+		}
+	}
+	return true
+}
+
+// bound is the most the remaining skills could add: the highest unused
+// tariffs, ignoring whether they chain.
+func (optimizer *routineOptimizer) bound() int {
+	remaining := routineLength - len(optimizer.path)
+	total := 0
+	for _, index := range optimizer.byValue {
+		if remaining == 0 {
+			break
+		}
+		if optimizer.uses[index] == 0 {
+			total += optimizer.tenths[index]
+			remaining--
+		}
+	}
+	return total
+}
+
+func (optimizer *routineOptimizer) record(tenths int) {
+	sorted := slices.Clone(optimizer.path)
+	slices.Sort(sorted)
+	keyParts := make([]string, len(sorted))
+	for i, index := range sorted {
+		keyParts[i] = strconv.Itoa(index)
+	}
+	key := strings.Join(keyParts, ",")
+	if slices.ContainsFunc(optimizer.best, func(result optimizerResult) bool { return result.key == key }) {
+		return
+	}
+
+	position := sort.Search(len(optimizer.best), func(i int) bool { return optimizer.best[i].tenths < tenths })
+	if position >= optimizer.top {
+		return
+	}
+	optimizer.best = slices.Insert(optimizer.best, position, optimizerResult{path: slices.Clone(optimizer.path), tenths: tenths, key: key})
+	if len(optimizer.best) > optimizer.top {
+		optimizer.best = optimizer.best[:optimizer.top]
+	}
+}
+
+func (s *Server) handleOptimizerPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "optimizer", s.newIndexPageData(r))
+}
+
+// handleOptimizeRoutine receives an athlete's repertoire as JSON and returns
+// the highest-tariff routines that can be built from it.
+func (s *Server) handleOptimizeRoutine(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var requestPayload struct {
+		Repertoire []skills.TrampolineSkill `json:"repertoire"`
+		Top        int                      `json:"top"`
+		Rules      string                   `json:"rules"`
+		Profile    string                   `json:"profile"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&requestPayload)
+	if err != nil {
+		log.Printf("Error decoding optimiser JSON payload: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	if len(requestPayload.Repertoire) > maxOptimizerRepertoire {
+		http.Error(w, fmt.Sprintf("Bad Request: repertoire has %d skills, the limit is %d", len(requestPayload.Repertoire), maxOptimizerRepertoire), 400)
+		return
+	}
+	top := requestPayload.Top
+	if top <= 0 {
+		top = defaultOptimizerTop
+	}
+	top = min(top, maxOptimizerTop)
+	rules, err := s.lookupTariffRules(requestPayload.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	profile, err := lookupProfile(requestPayload.Profile)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	routine.Routine(requestPayload.Repertoire).Prepare()
+
+	optimized := optimizeRoutine(requestPayload.Repertoire, top, routine.Options{Rules: rules, Profile: profile})
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(optimized)
+	if encodeErr != nil {
+		log.Printf("Error encoding optimiser JSON: %v", encodeErr)
+	}
+}
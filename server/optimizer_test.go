@@ -0,0 +1,79 @@
+package server
+
+import (
+	"math"
+	"testing"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+func repertoire(keys ...string) []skills.TrampolineSkill {
+	list := make([]skills.TrampolineSkill, len(keys))
+	for i, key := range keys {
+		list[i] = skills.CommonSkills[key]
+	}
+	return list
+}
+
+// sameTariff compares totals summed in floating point by validation.
+func sameTariff(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestOptimizeRoutineBest(t *testing.T) {
+	// Eleven skills from feet to feet: the best routine leaves out the
+	// lowest, Rudi (0.8), and the next best leaves out Double Full (0.9)
+	skillList := repertoire("miller", "fullRudi", "tripleBack", "fullFull", "trifHalfOut", "halfhalf",
+		"doubleBack", "halfOut", "randi", "doubleFullBack", "rudi")
+	data := optimizeRoutine(skillList, 2, routine.Options{Rules: skills.DefaultTariffRules()})
+
+	if !data.Exhaustive {
+		t.Errorf("search not exhaustive: %v", data.Messages)
+	}
+	if len(data.Routines) != 2 {
+		t.Fatalf("got %d routines, want 2: %v", len(data.Routines), data.Messages)
+	}
+	for i, want := range []float64{14.5, 14.4} {
+		result := data.Routines[i]
+		if result.TotalTariff != want {
+			t.Errorf("routine %d total = %.1f, want %.1f", i+1, result.TotalTariff, want)
+		}
+		if len(result.Skills) != routineLength {
+			t.Errorf("routine %d has %d skills, want %d", i+1, len(result.Skills), routineLength)
+		}
+		if !sameTariff(result.Validation.TotalTariff, want) || result.Validation.HasDuplicates {
+			t.Errorf("routine %d validation total = %.1f, duplicates %v; want %.1f without duplicates",
+				i+1, result.Validation.TotalTariff, result.Validation.HasDuplicates, want)
+		}
+	}
+}
+
+func TestOptimizeRoutineRepeatsConnectors(t *testing.T) {
+	// Four skills can only make ten by repeating some; repeats add nothing,
+	// so the best routine counts each skill once
+	skillList := repertoire("backDrop", "ballOut", "barani", "backSomersault")
+	data := optimizeRoutine(skillList, 1, routine.Options{Rules: skills.DefaultTariffRules()})
+
+	if len(data.Routines) != 1 {
+		t.Fatalf("got %d routines, want 1: %v", len(data.Routines), data.Messages)
+	}
+	result := data.Routines[0]
+	if result.TotalTariff != 1.8 {
+		t.Errorf("total = %.1f, want 1.8", result.TotalTariff)
+	}
+	if !sameTariff(result.Validation.TotalTariff, result.TotalTariff) {
+		t.Errorf("validation total = %.1f, want the optimiser's %.1f", result.Validation.TotalTariff, result.TotalTariff)
+	}
+	if result.Validation.HasInvalidTransitions || result.Validation.HasInvalidLandings || result.Validation.TenthSkillWarning {
+		t.Errorf("routine does not chain: %v", result.Validation.Messages)
+	}
+}
+
+func TestOptimizeRoutineNoRoutine(t *testing.T) {
+	// A back drop cannot get back to feet
+	data := optimizeRoutine(repertoire("backDrop"), 1, routine.Options{Rules: skills.DefaultTariffRules()})
+	if len(data.Routines) != 0 {
+		t.Errorf("got %d routines from a back drop alone, want none", len(data.Routines))
+	}
+}
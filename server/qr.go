@@ -0,0 +1,93 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tariffCalculator/qr"
+	"tariffCalculator/skills"
+)
+
+const (
+	defaultQRScale = 8
+	maxQRScale     = 32
+)
+
+// routineQRCode encodes the absolute /r/{code} link for a share code, so a
+// phone camera opens the routine in the calculator.
+func (s *Server) routineQRCode(r *http.Request, code string) (*qr.Code, error) {
+	if _, err := skills.DecodeRoutine(code); err != nil {
+		return nil, err
+	}
+	return qr.Encode([]byte(s.shareLink(r, code)), qr.M)
+}
+
+// handleQR serves QR codes of share links and reads scanned ones back:
+//
+//	GET  /qr/{code}.svg
+//	GET  /qr/{code}.png?scale=8   scale is pixels per module
+//	POST /qr/decode               "payload" is the scanned text, a /r/{code} link or bare code;
+//	                              answers the SharedRoutine validated with "rules" and "profile"
+func (s *Server) handleQR(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/qr/")
+	if name == "decode" {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method Not Allowed", 405)
+			return
+		}
+		code := shareCodeFromPayload(r.FormValue("payload"))
+		if code == "" {
+			http.Error(w, "Bad Request: empty payload", 400)
+			return
+		}
+		shared, err := s.loadSharedRoutine(r, code)
+		if err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), 400)
+			return
+		}
+		writeJSON(w, shared)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	code, format, found := strings.Cut(name, ".")
+	if !found || (format != "png" && format != "svg") {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+	symbol, err := s.routineQRCode(r, code)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	var out bytes.Buffer
+	if format == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		err = symbol.WriteSVG(&out)
+	} else {
+		scale := defaultQRScale
+		if value := r.FormValue("scale"); value != "" {
+			scale, err = strconv.Atoi(value)
+			if err != nil || scale < 1 || scale > maxQRScale {
+				http.Error(w, "Bad Request: scale must be 1-"+strconv.Itoa(maxQRScale), 400)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "image/png")
+		err = symbol.WritePNG(&out, scale)
+	}
+	if err != nil {
+		log.Printf("Error rendering QR code: %v", err)
+		http.Error(w, "Internal Server Error", 500)
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	out.WriteTo(w)
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tariffCalculator/accounts"
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+	"tariffCalculator/storage"
+)
+
+// RoutineSummary is a saved routine in the /routines list.
+type RoutineSummary struct {
+	Owner      string    `json:"owner"`
+	Name       string    `json:"name"`
+	SkillCount int       `json:"skillCount"`
+	NoteCount  int       `json:"noteCount"`
+	Updated    time.Time `json:"updated"`
+}
+
+func newRoutineSummary(routine storage.Routine) RoutineSummary {
+	return RoutineSummary{Owner: routine.Owner, Name: routine.Name, SkillCount: len(routine.Skills), NoteCount: len(routine.Notes), Updated: routine.Updated}
+}
+
+const maxNoteLength = 1000
+
+// handleRoutines serves the saved routine API for the signed-in user:
+//
+//	GET    /routines               list of RoutineSummary
+//	GET    /routines/{name}        the routine's skills, as accepted by parseRoutineFromRequest
+//	PUT    /routines/{name}        save the routine in the body or routineData value
+//	DELETE /routines/{name}
+//	GET    /routines/{name}/notes  the routine's notes
+//	POST   /routines/{name}/notes  {"skill", "text"} adds a note
+//
+// Coaches read and annotate their athletes' routines by adding
+// ?owner={athlete}; only the owner can save or delete.
+func (s *Server) handleRoutines(w http.ResponseWriter, r *http.Request) {
+	user, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	owner, err := s.routineOwner(r, user)
+	if writeStoreError(w, err) {
+		return
+	}
+
+	// Use the escaped path so names may contain an encoded "/"
+	escapedName, sub, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(r.URL.EscapedPath(), "/routines"), "/"), "/")
+	name, err := url.PathUnescape(escapedName)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	writable := owner == user.Username
+
+	switch {
+	case name == "" && r.Method == http.MethodGet:
+		summaries, err := s.listRoutines(owner)
+		if !writeStoreError(w, err) {
+			writeJSON(w, summaries)
+		}
+	case name == "" || (sub != "" && sub != "notes"):
+		http.Error(w, "Not Found", 404)
+	case sub == "notes" && r.Method == http.MethodGet:
+		routine, err := s.routines.Get(owner, name)
+		if !writeStoreError(w, err) {
+			writeJSON(w, routine.Notes)
+		}
+	case sub == "notes" && r.Method == http.MethodPost:
+		var request routineNoteRequest
+		if !decodeJSONBody(w, r, &request) {
+			return
+		}
+		notes, err := s.addRoutineNote(owner, name, user.Username, request)
+		if !writeStoreError(w, err) {
+			writeJSON(w, notes)
+		}
+	case sub == "" && r.Method == http.MethodGet:
+		routine, err := s.routines.Get(owner, name)
+		if !writeStoreError(w, err) {
+			writeJSON(w, routine.Skills)
+		}
+	case sub == "" && (r.Method == http.MethodPut || r.Method == http.MethodDelete) && !writable:
+		http.Error(w, "Forbidden", 403)
+	case sub == "" && r.Method == http.MethodPut:
+		skillList, err := parseRoutineFromRequest(r, nil)
+		if err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), 400)
+			return
+		}
+		summary, err := s.saveRoutine(owner, name, skillList)
+		if !writeStoreError(w, err) {
+			writeJSON(w, summary)
+		}
+	case sub == "" && r.Method == http.MethodDelete:
+		if !writeStoreError(w, s.routines.Delete(owner, name)) {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		http.Error(w, "Method Not Allowed", 405)
+	}
+}
+
+// routineOwner returns whose routines the request is for: the "owner" query
+// value, which must have shared them with the user, or the user's own.
+func (s *Server) routineOwner(r *http.Request, user accounts.User) (string, error) {
+	owner := r.URL.Query().Get("owner")
+	if owner == "" {
+		return user.Username, nil
+	}
+	if !s.accounts.CanView(user.Username, owner) {
+		return "", &requestError{status: 403, err: fmt.Errorf("%s has not shared routines with you", owner)}
+	}
+	return owner, nil
+}
+
+func (s *Server) listRoutines(owner string) ([]RoutineSummary, error) {
+	routines, err := s.routines.List(owner)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]RoutineSummary, len(routines))
+	for i, routine := range routines {
+		summaries[i] = newRoutineSummary(routine)
+	}
+	return summaries, nil
+}
+
+// saveRoutine stores a routine, keeping the notes of a routine it replaces.
+func (s *Server) saveRoutine(owner, name string, skillList []skills.TrampolineSkill) (RoutineSummary, error) {
+	if err := storage.ValidateName(name); err != nil {
+		return RoutineSummary{}, badRequest(err)
+	}
+	if len(skillList) == 0 {
+		return RoutineSummary{}, badRequest(errors.New("empty routine"))
+	}
+	routine.Routine(skillList).Prepare() // Fill in skill names for display
+	for i := range skillList {
+		skillList[i].SetTariff(s.rules)
+		skillList[i].LandingPosStr = skillList[i].LandingPosition().String()
+	}
+
+	var saved storage.Routine
+	err := s.routines.Update(owner, name, func(stored *storage.Routine, exists bool) error {
+		if !exists {
+			stored.Notes = []storage.Note{}
+		}
+		stored.Skills = skillList
+		stored.Updated = time.Now().UTC()
+		saved = *stored
+		return nil
+	})
+	if err != nil {
+		return RoutineSummary{}, err
+	}
+	return newRoutineSummary(saved), nil
+}
+
+type routineNoteRequest struct {
+	Skill int    `json:"skill"`
+	Text  string `json:"text"`
+}
+
+// addRoutineNote adds a note to a saved routine and returns all its notes.
+func (s *Server) addRoutineNote(owner, name, author string, request routineNoteRequest) ([]storage.Note, error) {
+	text := strings.TrimSpace(request.Text)
+	if text == "" || len(text) > maxNoteLength {
+		return nil, badRequest(fmt.Errorf("note must be 1-%d characters", maxNoteLength))
+	}
+	var notes []storage.Note
+	err := s.routines.Update(owner, name, func(stored *storage.Routine, exists bool) error {
+		if !exists {
+			return storage.ErrNotFound
+		}
+		if request.Skill < 0 || request.Skill > len(stored.Skills) {
+			return badRequest(fmt.Errorf("skill %d not in routine", request.Skill))
+		}
+		stored.Notes = append(stored.Notes, storage.Note{Author: author, Skill: request.Skill, Text: text, Created: time.Now().UTC()})
+		notes = stored.Notes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// writeStoreError answers a failed routine operation and reports whether it
+// did.
+func writeStoreError(w http.ResponseWriter, err error) bool {
+	var reqErr *requestError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &reqErr):
+		http.Error(w, http.StatusText(reqErr.status)+": "+reqErr.Error(), reqErr.status)
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, "Not Found", 404)
+	default:
+		log.Printf("Error accessing routine store: %v", err)
+		http.Error(w, "Internal Server Error", 500)
+	}
+	return true
+}
@@ -0,0 +1,357 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// --- Scoreboard Events ---
+
+const (
+	scoreboardReplay    = 200              // Score events kept per competition for streams that reconnect
+	scoreboardBuffer    = 64               // Events queued for a stream before it is dropped as too slow
+	scoreboardRetry     = 2 * time.Second  // Reconnection delay sent to browsers
+	scoreboardHeartbeat = 15 * time.Second // Comment sent on idle streams so proxies keep them open
+)
+
+// ScoreboardEvent is a score recorded in the competition manager, with the
+// standings of the category's round after it.
+type ScoreboardEvent struct {
+	ID            int              `json:"id"`
+	CompetitionID int              `json:"competitionId"`
+	CategoryID    int              `json:"categoryId"`
+	Category      string           `json:"category"`
+	Round         string           `json:"round"`
+	AthleteID     int              `json:"athleteId"`
+	Athlete       string           `json:"athlete"`
+	Club          string           `json:"club"`
+	RoutineKey    string           `json:"routineKey"`
+	Score         ScoreData        `json:"score"`
+	Rank          int              `json:"rank"` // The athlete's rank in Results
+	Results       []*RankedAthlete `json:"results"`
+	Time          time.Time        `json:"time"`
+}
+
+// ScoreboardStandings is a category's current round and its standings, sent
+// to a stream that has no score events to catch up from.
+type ScoreboardStandings struct {
+	CategoryID int              `json:"categoryId"`
+	Category   string           `json:"category"`
+	Round      string           `json:"round"`
+	Results    []*RankedAthlete `json:"results"`
+}
+
+// scoreboardHub fans score events out to the open scoreboard streams and
+// keeps each competition's recent ones, so a stream that reconnects gets what
+// it missed. Event IDs count up from 1 across all competitions.
+type scoreboardHub struct {
+	mu      sync.Mutex
+	lastID  int
+	recent  map[int][]ScoreboardEvent    // Per competition, oldest first, at most scoreboardReplay
+	dropped map[int]int                  // Per competition, ID of the newest event no longer kept
+	streams map[chan ScoreboardEvent]int // Competition ID each stream follows
+}
+
+func newScoreboardHub() *scoreboardHub {
+	return &scoreboardHub{recent: map[int][]ScoreboardEvent{}, dropped: map[int]int{}, streams: map[chan ScoreboardEvent]int{}}
+}
+
+// publish numbers the event and sends it to the competition's streams. A
+// stream whose queue is full is closed rather than waited for; its browser
+// reconnects and catches up from the recent events.
+func (hub *scoreboardHub) publish(event ScoreboardEvent) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.lastID++
+	event.ID = hub.lastID
+	recent := append(hub.recent[event.CompetitionID], event)
+	if len(recent) > scoreboardReplay {
+		hub.dropped[event.CompetitionID] = recent[len(recent)-scoreboardReplay-1].ID
+		recent = slices.Delete(recent, 0, len(recent)-scoreboardReplay)
+	}
+	hub.recent[event.CompetitionID] = recent
+	for stream, competitionID := range hub.streams {
+		if competitionID != event.CompetitionID {
+			continue
+		}
+		select {
+		case stream <- event:
+		default:
+			delete(hub.streams, stream)
+			close(stream)
+		}
+	}
+}
+
+// subscribe opens a stream of the competition's events after lastID. missed
+// are the kept events after lastID; complete is false if older ones the
+// stream has not seen are no longer kept, or lastID is from before a restart.
+func (hub *scoreboardHub) subscribe(competitionID, lastID int) (stream chan ScoreboardEvent, missed []ScoreboardEvent, complete bool) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	complete = lastID <= hub.lastID && lastID >= hub.dropped[competitionID]
+	if lastID > hub.lastID {
+		lastID = 0
+	}
+	for _, event := range hub.recent[competitionID] {
+		if event.ID > lastID {
+			missed = append(missed, event)
+		}
+	}
+	stream = make(chan ScoreboardEvent, scoreboardBuffer)
+	hub.streams[stream] = competitionID
+	return stream, missed, complete
+}
+
+func (hub *scoreboardHub) unsubscribe(stream chan ScoreboardEvent) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	delete(hub.streams, stream)
+}
+
+// publishScore sends a score just recorded to the competition's scoreboards.
+// Scores are only recorded by the competition's organiser, an account made
+// with the tariff organiser command, so the public streams carry nothing an
+// anonymous or self-registered account wrote. It is called with the
+// competitions lock held.
+func (s *Server) publishScore(competition *Competition, category *CompetitionCategory, athlete *CompetitionAthlete, routineKey string, score ScoreData) {
+	round := category.routineRound(routineKey)
+	results, err := category.results(round)
+	if err != nil {
+		log.Printf("Error ranking %s for the scoreboard: %v", category.Name, err)
+		return
+	}
+	event := ScoreboardEvent{
+		CompetitionID: competition.ID,
+		CategoryID:    category.ID,
+		Category:      category.Name,
+		Round:         round,
+		AthleteID:     athlete.ID,
+		Athlete:       athlete.Name,
+		Club:          athlete.Club,
+		RoutineKey:    routineKey,
+		Score:         score,
+		Results:       results,
+		Time:          time.Now(),
+	}
+	for _, result := range results {
+		if result.AthleteID == athlete.ID {
+			event.Rank = result.Rank
+		}
+	}
+	s.scoreboard.publish(event)
+}
+
+// routineRound returns the round a routine key is scored in.
+func (category *CompetitionCategory) routineRound(key string) string {
+	if category.FinalSize > 0 && !slices.Contains(category.Qualification.Routines, key) && slices.Contains(category.Final.Routines, key) {
+		return finalRound
+	}
+	return qualificationRound
+}
+
+// currentRound returns the final once any final routine has been scored, and
+// qualification until then.
+func (category *CompetitionCategory) currentRound() string {
+	if category.FinalSize == 0 {
+		return qualificationRound
+	}
+	for _, athlete := range category.Athletes {
+		for _, key := range category.Final.Routines {
+			if _, scored := athlete.Scores[key]; scored {
+				return finalRound
+			}
+		}
+	}
+	return qualificationRound
+}
+
+// standings returns the current standings of every category.
+func (competition *Competition) standings() []ScoreboardStandings {
+	standings := make([]ScoreboardStandings, 0, len(competition.Categories))
+	for _, category := range competition.Categories {
+		round := category.currentRound()
+		results, err := category.results(round)
+		if err != nil {
+			log.Printf("Error ranking %s for the scoreboard: %v", category.Name, err)
+			continue
+		}
+		standings = append(standings, ScoreboardStandings{CategoryID: category.ID, Category: category.Name, Round: round, Results: results})
+	}
+	return standings
+}
+
+// --- Scoreboard Handlers ---
+
+// ScoreboardPageData is the page data of a competition's scoreboard.
+// Presenter selects the full-screen view for a projector.
+type ScoreboardPageData struct {
+	IndexPageData
+	CompetitionID   int
+	CompetitionName string
+	Presenter       bool
+}
+
+// handleScoreboardPage serves the public scoreboard of a competition:
+//
+//	GET /scoreboard/{id}
+func (s *Server) handleScoreboardPage(w http.ResponseWriter, r *http.Request) {
+	data, ok := s.newScoreboardPageData(w, r)
+	if !ok {
+		return
+	}
+	s.handlePage(w, "scoreboard", data)
+}
+
+// handleScoreboardPresenter serves the scoreboard without navigation and in
+// large type, for a projector in the hall:
+//
+//	GET /scoreboard/{id}/presenter
+func (s *Server) handleScoreboardPresenter(w http.ResponseWriter, r *http.Request) {
+	data, ok := s.newScoreboardPageData(w, r)
+	if !ok {
+		return
+	}
+	data.Presenter = true
+	s.renderPage(w, "scoreboard", "presenter.html", data)
+}
+
+// newScoreboardPageData looks up the competition in the path, or writes a 404
+// response and reports false.
+func (s *Server) newScoreboardPageData(w http.ResponseWriter, r *http.Request) (ScoreboardPageData, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Not Found", 404)
+		return ScoreboardPageData{}, false
+	}
+	s.competitions.mu.Lock()
+	competition, exists := s.competitions.competitions[id]
+	var name string
+	if exists {
+		name = competition.Name
+	}
+	s.competitions.mu.Unlock()
+	if !exists {
+		http.Error(w, "Not Found", 404)
+		return ScoreboardPageData{}, false
+	}
+	return ScoreboardPageData{IndexPageData: s.newIndexPageData(r), CompetitionID: id, CompetitionName: name}, true
+}
+
+// handleScoreboardEvents streams a competition's scores as server-sent events:
+//
+//	GET /scoreboard/{id}/events
+//
+// Each score recorded is a "score" event carrying a ScoreboardEvent, with its
+// ID as the event ID. A browser that reconnects sends the last ID it saw, in
+// the Last-Event-ID header or, for a new EventSource, the lastEventId
+// parameter, and first gets the score events it missed. A new stream gets the
+// recent score events and then a "standings" event per category; so does one
+// that missed more events than are kept.
+func (s *Server) handleScoreboardEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Not Found", 404)
+		return
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	lastID := 0
+	if lastEventID != "" {
+		lastID, err = strconv.Atoi(lastEventID)
+		if err != nil || lastID < 0 {
+			http.Error(w, fmt.Sprintf("Bad Request: invalid last event ID %q", lastEventID), 400)
+			return
+		}
+	}
+
+	// Subscribe and take the standings under the competitions lock, which
+	// recordScore holds while publishing, so no score falls between the two
+	s.competitions.mu.Lock()
+	competition, exists := s.competitions.competitions[id]
+	if !exists {
+		s.competitions.mu.Unlock()
+		http.Error(w, "Not Found", 404)
+		return
+	}
+	stream, missed, complete := s.scoreboard.subscribe(id, lastID)
+	var standings []ScoreboardStandings
+	if lastEventID == "" || !complete {
+		standings = competition.standings()
+	}
+	s.competitions.mu.Unlock()
+	defer s.scoreboard.unsubscribe(stream)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop nginx buffering the stream
+	controller := http.NewResponseController(w)
+
+	_, err = fmt.Fprintf(w, "retry: %d\n\n", scoreboardRetry.Milliseconds())
+	for _, event := range missed {
+		if err == nil {
+			err = writeServerSentEvent(w, event.ID, "score", event)
+		}
+	}
+	for _, categoryStandings := range standings {
+		if err == nil {
+			err = writeServerSentEvent(w, 0, "standings", categoryStandings)
+		}
+	}
+	if err == nil {
+		err = controller.Flush()
+	}
+	if err != nil {
+		log.Printf("Error starting scoreboard stream: %v", err)
+		return
+	}
+
+	heartbeat := time.NewTicker(scoreboardHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, open := <-stream:
+			if !open {
+				// Dropped as too slow; the browser reconnects and catches up
+				return
+			}
+			err = writeServerSentEvent(w, event.ID, "score", event)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		}
+		if err == nil {
+			err = controller.Flush()
+		}
+		if err != nil {
+			// The browser has gone away
+			return
+		}
+	}
+}
+
+// writeServerSentEvent writes an event named name with v as its JSON data,
+// and id as its ID unless it is 0.
+func writeServerSentEvent(w io.Writer, id int, name string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if id != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+	return err
+}
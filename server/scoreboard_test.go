@@ -0,0 +1,41 @@
+package server
+
+import "testing"
+
+// TestScoreboardReplay checks each competition keeps its own recent events,
+// so a busy competition does not push a quiet one's out.
+func TestScoreboardReplay(t *testing.T) {
+	hub := newScoreboardHub()
+	hub.publish(ScoreboardEvent{CompetitionID: 2})
+	for range scoreboardReplay + 1 {
+		hub.publish(ScoreboardEvent{CompetitionID: 1})
+	}
+
+	tests := []struct {
+		competitionID int
+		lastID        int
+		missed        int
+		complete      bool
+	}{
+		{2, 0, 1, true},
+		{2, 1, 0, true},
+		{1, 0, scoreboardReplay, false},
+		{1, 1, scoreboardReplay, false},
+		{1, 2, scoreboardReplay, true},
+		{1, scoreboardReplay + 2, 0, true},
+		{1, scoreboardReplay + 3, scoreboardReplay, false}, // From before a restart
+		{3, 0, 0, true},
+	}
+	for _, test := range tests {
+		stream, missed, complete := hub.subscribe(test.competitionID, test.lastID)
+		hub.unsubscribe(stream)
+		if len(missed) != test.missed || complete != test.complete {
+			t.Errorf("subscribe(%d, %d) = %d events, complete %v; want %d, %v", test.competitionID, test.lastID, len(missed), complete, test.missed, test.complete)
+		}
+		for _, event := range missed {
+			if event.CompetitionID != test.competitionID || event.ID <= test.lastID && test.lastID <= hub.lastID {
+				t.Errorf("subscribe(%d, %d) replayed event %d of competition %d", test.competitionID, test.lastID, event.ID, event.CompetitionID)
+			}
+		}
+	}
+}
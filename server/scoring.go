@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+const (
+	maxSkillDeduction   = 0.5
+	maxLandingDeduction = 0.3
+	maxHorizontalScore  = 10.0
+)
+
+// ScoreRequest is a routine with the judges' marks for it. Execution holds one
+// row per judge with that judge's deduction for each completed skill.
+type ScoreRequest struct {
+	Routine                []skills.TrampolineSkill `json:"routine"`
+	Rules                  string                   `json:"rules"`
+	Profile                string                   `json:"profile"`
+	Interrupted            bool                     `json:"interrupted"`
+	CompletedSkills        int                      `json:"completedSkills"` // Skills performed before the interruption
+	Execution              [][]float64              `json:"execution"`
+	Landing                []float64                `json:"landing"` // One landing deduction per judge
+	HorizontalDisplacement float64                  `json:"horizontalDisplacement"`
+	TimeOfFlight           float64                  `json:"timeOfFlight"`
+	Penalty                float64                  `json:"penalty"`
+}
+
+type ScoreData struct {
+	Difficulty             float64        `json:"difficulty"`
+	Execution              float64        `json:"execution"`
+	HorizontalDisplacement float64        `json:"horizontalDisplacement"`
+	TimeOfFlight           float64        `json:"timeOfFlight"`
+	Penalty                float64        `json:"penalty"`
+	Final                  float64        `json:"final"`
+	CompletedSkills        int            `json:"completedSkills"`
+	Interrupted            bool           `json:"interrupted"`
+	SkillDeductions        []float64      `json:"skillDeductions"` // Median deduction per completed skill
+	LandingDeduction       float64        `json:"landingDeduction"`
+	Validation             routine.Result `json:"validation"` // Validation of the completed skills
+}
+
+// calculateScore works out the final score as D + E + H + T - penalties:
+//
+//   - D is the tariff of the completed skills from routine validation.
+//   - E: each skill and the landing take the median of the judges'
+//     deductions (the mean of the middle two for an even panel). Each
+//     completed skill is worth 1.0 less its deduction, less the landing
+//     deduction after a completed routine, and the total is doubled, so a
+//     perfect ten-skill routine scores 20.0.
+//   - H and T are the measured horizontal displacement and time of flight
+//     scores for the skills performed.
+//
+// An interrupted routine scores only the skills completed before the
+// interruption and gets no landing deduction.
+func calculateScore(request ScoreRequest, opts routine.Options) (ScoreData, error) {
+	completed := min(len(request.Routine), routineLength)
+	if request.Interrupted {
+		completed = max(min(completed, request.CompletedSkills), 0)
+	}
+	data := ScoreData{
+		CompletedSkills: completed,
+		Interrupted:     request.Interrupted,
+		SkillDeductions: make([]float64, completed),
+	}
+
+	if len(request.Execution) == 0 {
+		return data, errors.New("no execution judges")
+	}
+	for judge, deductions := range request.Execution {
+		if len(deductions) < completed {
+			return data, fmt.Errorf("judge %d has %d deductions for %d completed skills", judge+1, len(deductions), completed)
+		}
+		for i, deduction := range deductions[:completed] {
+			if deduction < 0 || deduction > maxSkillDeduction {
+				return data, fmt.Errorf("judge %d skill %d deduction %.1f outside 0.0-%.1f", judge+1, i+1, deduction, maxSkillDeduction)
+			}
+		}
+	}
+	if !data.Interrupted && len(request.Landing) != len(request.Execution) {
+		return data, fmt.Errorf("%d landing deductions for %d judges", len(request.Landing), len(request.Execution))
+	}
+	for judge, deduction := range request.Landing {
+		if deduction < 0 || deduction > maxLandingDeduction {
+			return data, fmt.Errorf("judge %d landing deduction %.1f outside 0.0-%.1f", judge+1, deduction, maxLandingDeduction)
+		}
+	}
+	if request.HorizontalDisplacement < 0 || request.HorizontalDisplacement > maxHorizontalScore {
+		return data, fmt.Errorf("horizontal displacement %.2f outside 0-%.0f", request.HorizontalDisplacement, maxHorizontalScore)
+	}
+	if request.TimeOfFlight < 0 || request.Penalty < 0 {
+		return data, errors.New("time of flight and penalty cannot be negative")
+	}
+
+	data.Validation = routine.Routine(request.Routine[:completed]).Validate(opts)
+	data.Difficulty = roundScore(data.Validation.TotalTariff)
+
+	execution := 0.0
+	for i := 0; i < completed; i++ {
+		marks := make([]float64, len(request.Execution))
+		for judge := range request.Execution {
+			marks[judge] = request.Execution[judge][i]
+		}
+		data.SkillDeductions[i] = roundScore(median(marks))
+		execution += 1.0 - data.SkillDeductions[i]
+	}
+	if !data.Interrupted && completed > 0 {
+		data.LandingDeduction = roundScore(median(request.Landing))
+		execution -= data.LandingDeduction
+	}
+	data.Execution = roundScore(max(execution, 0) * 2)
+	data.HorizontalDisplacement = request.HorizontalDisplacement
+	data.TimeOfFlight = request.TimeOfFlight
+	data.Penalty = request.Penalty
+	data.Final = roundScore(max(data.Difficulty+data.Execution+data.HorizontalDisplacement+data.TimeOfFlight-data.Penalty, 0))
+	return data, nil
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// roundScore rounds to the three decimal places scores are published with.
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
+
+func (s *Server) handleScoringPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "scoring", s.newIndexPageData(r))
+}
+
+// handleCalculateScore receives a ScoreRequest as JSON and returns the score JSON.
+func (s *Server) handleCalculateScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var request ScoreRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&request)
+	if err != nil {
+		log.Printf("Error decoding score JSON payload: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	rules, err := s.lookupTariffRules(request.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	profile, err := lookupProfile(request.Profile)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	routine.Routine(request.Routine).Prepare()
+
+	score, err := calculateScore(request, routine.Options{Rules: rules, Profile: profile})
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(score)
+	if encodeErr != nil {
+		log.Printf("Error encoding score JSON: %v", encodeErr)
+	}
+}
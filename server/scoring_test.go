@@ -0,0 +1,113 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+// scoringRoutine is ten skills worth 14.5, the first four 7.4.
+func scoringRoutine() []skills.TrampolineSkill {
+	skillList := repertoire("miller", "fullRudi", "tripleBack", "fullFull", "trifHalfOut",
+		"halfhalf", "doubleBack", "halfOut", "randi", "doubleFullBack")
+	routine.Routine(skillList).Prepare()
+	return skillList
+}
+
+// marks gives every judge the same deduction for each of ten skills.
+func marks(deductions ...float64) [][]float64 {
+	execution := make([][]float64, len(deductions))
+	for judge, deduction := range deductions {
+		execution[judge] = slices.Repeat([]float64{deduction}, routineLength)
+	}
+	return execution
+}
+
+func TestCalculateScore(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ScoreRequest
+		want    ScoreData
+	}{
+		{
+			name:    "perfect routine",
+			request: ScoreRequest{Execution: marks(0, 0, 0), Landing: []float64{0, 0, 0}, HorizontalDisplacement: 9.5, TimeOfFlight: 16.2},
+			want:    ScoreData{Difficulty: 14.5, Execution: 20, HorizontalDisplacement: 9.5, TimeOfFlight: 16.2, Final: 60.2, CompletedSkills: 10},
+		},
+		{
+			name:    "median of an odd panel",
+			request: ScoreRequest{Execution: marks(0.3, 0.1, 0.2), Landing: []float64{0.1, 0.3, 0.2}, HorizontalDisplacement: 9, TimeOfFlight: 15, Penalty: 0.3},
+			want:    ScoreData{Difficulty: 14.5, Execution: 15.6, HorizontalDisplacement: 9, TimeOfFlight: 15, Penalty: 0.3, Final: 53.8, CompletedSkills: 10, LandingDeduction: 0.2},
+		},
+		{
+			name:    "mean of the middle two of an even panel",
+			request: ScoreRequest{Execution: marks(0.1, 0.3, 0.1, 0.2), Landing: []float64{0, 0.1, 0.1, 0}},
+			want:    ScoreData{Difficulty: 14.5, Execution: 16.9, Final: 31.4, CompletedSkills: 10, LandingDeduction: 0.05},
+		},
+		{
+			name:    "interrupted after four skills",
+			request: ScoreRequest{Interrupted: true, CompletedSkills: 4, Execution: marks(0.1, 0.1, 0.1), HorizontalDisplacement: 4, TimeOfFlight: 6},
+			want:    ScoreData{Difficulty: 7.4, Execution: 7.2, HorizontalDisplacement: 4, TimeOfFlight: 6, Final: 24.6, CompletedSkills: 4, Interrupted: true},
+		},
+		{
+			name:    "interrupted before the first skill",
+			request: ScoreRequest{Interrupted: true, Execution: marks(0.1)},
+			want:    ScoreData{Interrupted: true},
+		},
+		{
+			name:    "penalty larger than the score",
+			request: ScoreRequest{Interrupted: true, CompletedSkills: 1, Execution: marks(0.5), Penalty: 10},
+			want:    ScoreData{Difficulty: 2.1, Execution: 1, Penalty: 10, CompletedSkills: 1, Interrupted: true},
+		},
+	}
+	for _, test := range tests {
+		test.request.Routine = scoringRoutine()
+		got, err := calculateScore(test.request, routine.Options{Rules: skills.DefaultTariffRules()})
+		if err != nil {
+			t.Errorf("%s: error: %v", test.name, err)
+			continue
+		}
+		if got.Difficulty != test.want.Difficulty || got.Execution != test.want.Execution ||
+			got.HorizontalDisplacement != test.want.HorizontalDisplacement || got.TimeOfFlight != test.want.TimeOfFlight ||
+			got.Penalty != test.want.Penalty || got.Final != test.want.Final {
+			t.Errorf("%s: D %.3f E %.3f H %.3f T %.3f P %.3f = %.3f; want D %.3f E %.3f H %.3f T %.3f P %.3f = %.3f", test.name,
+				got.Difficulty, got.Execution, got.HorizontalDisplacement, got.TimeOfFlight, got.Penalty, got.Final,
+				test.want.Difficulty, test.want.Execution, test.want.HorizontalDisplacement, test.want.TimeOfFlight, test.want.Penalty, test.want.Final)
+		}
+		if got.CompletedSkills != test.want.CompletedSkills || got.Interrupted != test.want.Interrupted || got.LandingDeduction != test.want.LandingDeduction {
+			t.Errorf("%s: completed %d, interrupted %v, landing %.3f; want %d, %v, %.3f", test.name,
+				got.CompletedSkills, got.Interrupted, got.LandingDeduction, test.want.CompletedSkills, test.want.Interrupted, test.want.LandingDeduction)
+		}
+		if len(got.SkillDeductions) != got.CompletedSkills || len(got.Validation.Skills) != got.CompletedSkills {
+			t.Errorf("%s: %d skill deductions and %d validated skills for %d completed", test.name,
+				len(got.SkillDeductions), len(got.Validation.Skills), got.CompletedSkills)
+		}
+	}
+}
+
+func TestCalculateScoreErrors(t *testing.T) {
+	short := marks(0, 0)
+	short[1] = short[1][:9]
+	tests := []struct {
+		name    string
+		request ScoreRequest
+	}{
+		{"no judges", ScoreRequest{Landing: []float64{}}},
+		{"judge missing a skill", ScoreRequest{Execution: short, Landing: []float64{0, 0}}},
+		{"skill deduction too large", ScoreRequest{Execution: marks(0, 0.6), Landing: []float64{0, 0}}},
+		{"negative skill deduction", ScoreRequest{Execution: marks(-0.1), Landing: []float64{0}}},
+		{"landing deduction per judge", ScoreRequest{Execution: marks(0, 0), Landing: []float64{0}}},
+		{"landing deduction too large", ScoreRequest{Execution: marks(0), Landing: []float64{0.4}}},
+		{"horizontal displacement too large", ScoreRequest{Execution: marks(0), Landing: []float64{0}, HorizontalDisplacement: 10.5}},
+		{"negative time of flight", ScoreRequest{Execution: marks(0), Landing: []float64{0}, TimeOfFlight: -1}},
+		{"negative penalty", ScoreRequest{Execution: marks(0), Landing: []float64{0}, Penalty: -0.1}},
+	}
+	for _, test := range tests {
+		test.request.Routine = scoringRoutine()
+		if _, err := calculateScore(test.request, routine.Options{Rules: skills.DefaultTariffRules()}); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
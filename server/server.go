@@ -0,0 +1,268 @@
+// Package server is the tariff calculator web application. NewServer builds
+// it from a Config as an http.Handler, so it can run on its own, be mounted
+// under a sub-path of a larger application or be started several times in
+// one process, e.g. in tests. Servers share nothing but the common skill
+// catalogue of the skills package.
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"tariffCalculator/accounts"
+	"tariffCalculator/skills"
+	"tariffCalculator/storage"
+)
+
+// Disciplines that can be listed in Config.Disciplines. The trampoline
+// calculator, and the optimiser, scoring and competition tools built on it,
+// are always served.
+const (
+	Trampoline = "trampoline"
+	Synchro    = "synchro"
+	DMT        = "dmt"
+	Tumbling   = "tumbling"
+)
+
+// Disciplines lists every discipline.
+var Disciplines = []string{Trampoline, Synchro, DMT, Tumbling}
+
+// DefaultDisciplines are served when Config.Disciplines is nil. DMT and
+// tumbling are left out until their tariff tables have been checked against
+// the FIG Double Mini-Trampoline and Tumbling Codes of Points; list them in
+// Config.Disciplines to serve them.
+var DefaultDisciplines = []string{Trampoline, Synchro}
+
+// Config configures a Server. Zero values select the defaults.
+type Config struct {
+	Port        string             // Port ListenAndServe listens on, "8080" by default
+	BasePath    string             // Path the server is mounted under, e.g. "/tariff"; empty at the root
+	TemplateDir string             // Page templates on disk, instead of the embedded ones, e.g. while editing them
+	StaticDir   string             // Files served under /static/ from disk, instead of the embedded ones
+	Disciplines []string           // Disciplines with pages and routes, DefaultDisciplines by default
+	Rules       string             // Tariff rule set used when a request selects none, skills.DefaultTariffRulesID by default
+	Accounts    *accounts.Registry // Local accounts, kept in memory by default
+	Routines    storage.Store      // Saved routines, kept in memory by default
+}
+
+// Server is the web application. It serves every request itself, so it is
+// mounted with mux.Handle(config.BasePath+"/", server) and needs no
+// http.StripPrefix.
+type Server struct {
+	config       Config
+	rules        skills.TariffRules
+	disciplines  map[string]bool
+	static       fs.FS
+	tmpl         *template.Template
+	pages        map[string]*template.Template // One template set per page, a clone of tmpl with the page's "content"
+	accounts     *accounts.Registry
+	routines     storage.Store
+	competitions *competitionStore
+	scoreboard   *scoreboardHub
+	handler      http.Handler
+}
+
+// NewServer checks config, loads the templates and sets up the routes.
+func NewServer(config Config) (*Server, error) {
+	if config.Port == "" {
+		config.Port = "8080"
+	}
+	config.BasePath = strings.TrimSuffix(config.BasePath, "/")
+	if config.BasePath != "" && !strings.HasPrefix(config.BasePath, "/") {
+		return nil, fmt.Errorf("base path %q must start with /", config.BasePath)
+	}
+	if config.Disciplines == nil {
+		config.Disciplines = DefaultDisciplines
+	}
+	if config.Rules == "" {
+		config.Rules = skills.DefaultTariffRulesID
+	}
+	if config.Accounts == nil {
+		config.Accounts = accounts.NewRegistry()
+	}
+	if config.Routines == nil {
+		config.Routines = storage.NewMemoryStore()
+	}
+
+	rules, exists := skills.GetTariffRules(config.Rules)
+	if !exists {
+		return nil, fmt.Errorf("unknown tariff rule set %q", config.Rules)
+	}
+	s := &Server{
+		config:       config,
+		rules:        rules,
+		disciplines:  map[string]bool{Trampoline: true},
+		pages:        map[string]*template.Template{},
+		accounts:     config.Accounts,
+		routines:     config.Routines,
+		competitions: &competitionStore{competitions: map[int]*Competition{}},
+		scoreboard:   newScoreboardHub(),
+	}
+	for _, discipline := range config.Disciplines {
+		if !slices.Contains(Disciplines, discipline) {
+			return nil, fmt.Errorf("unknown discipline %q", discipline)
+		}
+		s.disciplines[discipline] = true
+	}
+	var err error
+	if s.static, err = assetFS(config.StaticDir, "static"); err != nil {
+		return nil, err
+	}
+	if err := s.loadTemplates(); err != nil {
+		return nil, err
+	}
+	s.handler = s.withSession(s.routes())
+	if config.BasePath != "" {
+		s.handler = http.StripPrefix(config.BasePath, s.handler)
+	}
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.config.BasePath != "" && r.URL.Path == s.config.BasePath {
+		http.Redirect(w, r, s.config.BasePath+"/", http.StatusMovedPermanently)
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves on config.Port until the listener fails.
+func (s *Server) ListenAndServe() error {
+	log.Printf("Starting server on :%s%s/\n", s.config.Port, s.config.BasePath)
+	return http.ListenAndServe(":"+s.config.Port, s)
+}
+
+// path returns the URL path of a route, below the base path.
+func (s *Server) path(route string) string {
+	return s.config.BasePath + route
+}
+
+// --- Routes ---
+
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.StripPrefix("/static/", staticFileServer(s.static)))
+
+	mux.HandleFunc("/{$}", s.handleIndex)
+	mux.HandleFunc("/skill-form-fragment", s.handleSkillFormFragment)
+	mux.HandleFunc("/skill-inputs-fragment", s.handleSkillInputsFragment)
+	mux.HandleFunc("/edit-skill-form-data/", s.handleEditSkillFormData)
+	mux.HandleFunc("/calculate-skill", s.handleCalculateSingleSkill)
+	mux.HandleFunc("/calculate-notation", s.handleCalculateNotation)
+	mux.HandleFunc("/evaluate-skill-fragment", s.handleEvaluateSkillFragment)
+	mux.HandleFunc("/validate-routine-client-state", s.handleValidateRoutineClientState)
+	mux.HandleFunc("/common-skills-options", s.handleCommonSkillsOptions)
+
+	// Share links and their QR codes
+	mux.HandleFunc("/share-code", s.handleShareCode)
+	mux.HandleFunc("/r/", s.handleSharedRoutine)
+	mux.HandleFunc("/qr/", s.handleQR)
+
+	// JSON API for other tools
+	mux.HandleFunc(apiPrefix, s.handleAPI)
+
+	// Accounts
+	mux.HandleFunc("/account", s.handleAccountPage)
+	mux.HandleFunc("/account/", s.handleAccount)
+
+	// Saved routines
+	mux.HandleFunc("/routines", s.handleRoutines)
+	mux.HandleFunc("/routines/", s.handleRoutines)
+
+	// Routine optimiser
+	mux.HandleFunc("/optimizer", s.handleOptimizerPage)
+	mux.HandleFunc("/optimize-routine", s.handleOptimizeRoutine)
+
+	// Competition scoring
+	mux.HandleFunc("/scoring", s.handleScoringPage)
+	mux.HandleFunc("/calculate-score", s.handleCalculateScore)
+
+	// Competition cards
+	mux.HandleFunc("/card", s.handleCardPage)
+	mux.HandleFunc("/competition-card", s.handleCompetitionCard)
+
+	// Competition manager
+	mux.HandleFunc("/competition-manager", s.handleCompetitionsPage)
+	mux.HandleFunc("/competitions", s.handleCompetitions)
+	mux.HandleFunc("/competitions/", s.handleCompetitions)
+
+	// Public scoreboard, updated live as scores are recorded
+	mux.HandleFunc("GET /scoreboard/{id}", s.handleScoreboardPage)
+	mux.HandleFunc("GET /scoreboard/{id}/presenter", s.handleScoreboardPresenter)
+	mux.HandleFunc("GET /scoreboard/{id}/events", s.handleScoreboardEvents)
+
+	// Double mini-trampoline
+	if s.disciplines[DMT] {
+		mux.HandleFunc("/dmt", s.handleDMTPage)
+		mux.HandleFunc("/dmt/validate-pass", s.handleDMTValidatePass)
+	}
+
+	// Tumbling
+	if s.disciplines[Tumbling] {
+		mux.HandleFunc("/tumbling", s.handleTumblingPage)
+		mux.HandleFunc("/tumbling/validate-pass", s.handleTumblingValidatePass)
+	}
+
+	// Synchronised trampoline
+	if s.disciplines[Synchro] {
+		mux.HandleFunc("/synchro", s.handleSynchroPage)
+		mux.HandleFunc("/synchro/validate", s.handleValidateSynchro)
+	}
+	return mux
+}
+
+// --- Templates ---
+
+// pageNames are the pages with their own "content" in templates/pages/.
+var pageNames = []string{"dmt", "tumbling", "synchro", "optimizer", "scoring", "competitions", "scoreboard", "card", "account"}
+
+func (s *Server) loadTemplates() error {
+	templates, err := assetFS(s.config.TemplateDir, "templates")
+	if err != nil {
+		return err
+	}
+
+	// path and basePath build links below the base path: {{path "/dmt"}} in
+	// HTML and basePath + "/dmt" in scripts.
+	funcs := template.FuncMap{
+		"path":     s.path,
+		"basePath": func() string { return s.config.BasePath },
+	}
+	s.tmpl, err = template.New("base.html").Funcs(funcMap).Funcs(funcs).ParseFS(templates, "*.html")
+	if err != nil {
+		return fmt.Errorf("loading templates: %w", err)
+	}
+
+	for _, page := range pageNames {
+		pageTmpl, err := template.Must(s.tmpl.Clone()).ParseFS(templates, "pages/"+page+".html")
+		if err != nil {
+			return fmt.Errorf("loading %s page template: %w", page, err)
+		}
+		s.pages[page] = pageTmpl
+	}
+	return nil
+}
+
+// --- Static File Server ---
+
+// staticFileServer serves the front-end files, embedded in the binary unless
+// Config.StaticDir is set.
+func staticFileServer(files fs.FS) http.Handler {
+	fs := http.FileServerFS(files)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "public, max-age=604800")
+		if strings.HasSuffix(r.URL.Path, ".js") {
+			w.Header().Set("Content-Type", "application/javascript")
+		}
+		if strings.HasSuffix(r.URL.Path, ".css") {
+			w.Header().Set("Content-Type", "text/css")
+		}
+		fs.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+// SharedRoutine is a routine opened from a /r/{code} link, validated so the
+// page can describe it before the calculator has loaded.
+type SharedRoutine struct {
+	Code       string                   `json:"code"`
+	Routine    []skills.TrampolineSkill `json:"routine"`
+	Validation routine.Result           `json:"validation"`
+}
+
+// Summary describes the routine for link previews, e.g. "10 skills, tariff 12.4".
+func (shared *SharedRoutine) Summary() string {
+	return fmt.Sprintf("%d skills, tariff %.1f", len(shared.Routine), shared.Validation.TotalTariff)
+}
+
+// handleShareCode receives routine JSON like /validate-routine-client-state
+// and returns its share code and link.
+func (s *Server) handleShareCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	routine, err := parseRoutineFromRequest(r, nil)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	if len(routine) == 0 {
+		http.Error(w, "Bad Request: empty routine", 400)
+		return
+	}
+	code, err := skills.EncodeRoutine(routine)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	writeJSON(w, map[string]string{"code": code, "path": s.path("/r/" + code)})
+}
+
+// handleSharedRoutine opens the calculator with the routine from a share
+// code, validated with the "rules" and "profile" query values if given.
+func (s *Server) handleSharedRoutine(w http.ResponseWriter, r *http.Request) {
+	shared, err := s.loadSharedRoutine(r, strings.TrimPrefix(r.URL.Path, "/r/"))
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	data := s.newIndexPageData(r)
+	data.Shared = shared
+	err = s.tmpl.ExecuteTemplate(w, "base.html", data)
+	if err != nil {
+		log.Printf("Error executing base template for shared routine: %v", err)
+		http.Error(w, "Internal Server Error", 500)
+	}
+}
+
+// loadSharedRoutine decodes a share code and validates the routine with the
+// request's "rules" and "profile" values.
+func (s *Server) loadSharedRoutine(r *http.Request, code string) (*SharedRoutine, error) {
+	skillList, err := skills.DecodeRoutine(code)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.tariffRulesFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := lookupProfile(r.FormValue("profile"))
+	if err != nil {
+		return nil, err
+	}
+
+	routine.Routine(skillList).Prepare()
+	for i := range skillList {
+		skillList[i].SetTariff(rules)
+		skillList[i].LandingPosStr = skillList[i].LandingPosition().String()
+	}
+	return &SharedRoutine{
+		Code:       code,
+		Routine:    skillList,
+		Validation: routine.Routine(skillList).Validate(routine.Options{Rules: rules, Profile: profile}),
+	}, nil
+}
+
+// shareLink returns the absolute /r/{code} link for a share code on the
+// host the request came to.
+func (s *Server) shareLink(r *http.Request, code string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + s.path("/r/"+code)
+}
+
+// shareCodeFromPayload returns the share code in a scanned or pasted
+// payload: a /r/{code} link or the bare code.
+func shareCodeFromPayload(payload string) string {
+	payload = strings.TrimSpace(payload)
+	if i := strings.LastIndex(payload, "/r/"); i >= 0 {
+		payload = payload[i+len("/r/"):]
+	}
+	if i := strings.IndexAny(payload, "?#/"); i >= 0 {
+		payload = payload[:i]
+	}
+	return payload
+}
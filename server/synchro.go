@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"tariffCalculator/routine"
+	"tariffCalculator/skills"
+)
+
+// SynchroValidationData holds both athletes' routine validation and the
+// comparison between them.
+type SynchroValidationData struct {
+	AthleteA      routine.Result `json:"athleteA"`
+	AthleteB      routine.Result `json:"athleteB"`
+	SkillsMatch   []bool         `json:"skillsMatch"`   // Per position, true if both performed the same skill
+	InterruptedAt int            `json:"interruptedAt"` // Index of the first mismatch, -1 if none
+	PairTariff    float64        `json:"pairTariff"`
+	Messages      []string       `json:"messages"`
+}
+
+// performSynchroValidation validates each routine and
+// compares them position by position with TrampolineSkill.Equal.
+//
+// Synchro difficulty is credited only while the partners perform the same
+// skills: the first mismatch interrupts the pair routine, and the pair tariff
+// is the tariff of the identical skills before it.
+func performSynchroValidation(routineA, routineB []skills.TrampolineSkill, opts routine.Options) SynchroValidationData {
+	data := SynchroValidationData{
+		AthleteA:      routine.Routine(routineA).Validate(opts),
+		AthleteB:      routine.Routine(routineB).Validate(opts),
+		InterruptedAt: -1,
+		Messages:      []string{},
+	}
+
+	length := max(len(routineA), len(routineB))
+	data.SkillsMatch = make([]bool, length)
+	for i := 0; i < length; i++ {
+		if i < len(routineA) && i < len(routineB) {
+			data.SkillsMatch[i] = routineA[i].Equal(&routineB[i])
+		}
+		if !data.SkillsMatch[i] {
+			data.Messages = append(data.Messages, fmt.Sprintf("Skill %d: Athletes Perform Different Skills", i+1))
+			if data.InterruptedAt == -1 {
+				data.InterruptedAt = i
+			}
+		}
+	}
+
+	identical := routineA
+	if data.InterruptedAt != -1 {
+		identical = routineA[:min(data.InterruptedAt, len(routineA))]
+		data.Messages = append(data.Messages, fmt.Sprintf("Pair Routine Interrupted At Skill %d", data.InterruptedAt+1))
+	}
+	data.PairTariff = routine.Routine(identical).Validate(opts).TotalTariff
+	return data
+}
+
+func (s *Server) handleSynchroPage(w http.ResponseWriter, r *http.Request) {
+	s.handlePage(w, "synchro", s.newIndexPageData(r))
+}
+
+// handleValidateSynchro receives both athletes' routines as JSON and returns the synchro validation JSON.
+func (s *Server) handleValidateSynchro(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", 405)
+		return
+	}
+	var requestPayload struct {
+		AthleteA []skills.TrampolineSkill `json:"athleteA"`
+		AthleteB []skills.TrampolineSkill `json:"athleteB"`
+		Rules    string                   `json:"rules"`
+		Profile  string                   `json:"profile"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&requestPayload)
+	if err != nil {
+		log.Printf("Error decoding synchro JSON payload: %v", err)
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	rules, err := s.lookupTariffRules(requestPayload.Rules)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+	profile, err := lookupProfile(requestPayload.Profile)
+	if err != nil {
+		http.Error(w, "Bad Request: "+err.Error(), 400)
+		return
+	}
+
+	for _, athleteRoutine := range []routine.Routine{requestPayload.AthleteA, requestPayload.AthleteB} {
+		if err := athleteRoutine.CheckLength(); err != nil {
+			http.Error(w, "Bad Request: "+err.Error(), 400)
+			return
+		}
+	}
+
+	routine.Routine(requestPayload.AthleteA).Prepare()
+	routine.Routine(requestPayload.AthleteB).Prepare()
+
+	validationData := performSynchroValidation(requestPayload.AthleteA, requestPayload.AthleteB, routine.Options{Rules: rules, Profile: profile})
+	w.Header().Set("Content-Type", "application/json")
+	encodeErr := json.NewEncoder(w).Encode(validationData)
+	if encodeErr != nil {
+		log.Printf("Error encoding synchro validation JSON: %v", encodeErr)
+	}
+}
@@ -0,0 +1,134 @@
+package skills
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// The live common skill catalogue. It starts as CommonSkills and is replaced
+// as a whole by SetCatalogue, so maps returned by Catalogue are never
+// modified and callers must not modify them either.
+var (
+	catalogueMu sync.RWMutex
+	catalogue   = CommonSkills
+)
+
+// Catalogue returns the live common skill catalogue, keyed like CommonSkills.
+func Catalogue() map[string]TrampolineSkill {
+	catalogueMu.RLock()
+	defer catalogueMu.RUnlock()
+	return catalogue
+}
+
+// SetCatalogue replaces the live catalogue.
+func SetCatalogue(skills map[string]TrampolineSkill) {
+	catalogueMu.Lock()
+	defer catalogueMu.Unlock()
+	catalogue = skills
+}
+
+// LoadCatalogue reads a JSON object of catalogue entries keyed like
+// CommonSkills, e.g.
+//
+//	{"fullInFullOut": {"name": "Full In Full Out", "rotation": 8, "twist_distribution": [2, 2],
+//	  "takeoff_position": "Feet", "shape": "Straight", "backward": true}}
+//
+// Entries are added to the built-in CommonSkills, replacing any built-in with
+// the same key. Every entry is checked with Validate and must have a name, a
+// known takeoff position and shape, and a valid landing; all problems are reported together and nothing is returned
+// unless the whole file is valid.
+func LoadCatalogue(path string) (map[string]TrampolineSkill, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]TrampolineSkill
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var problems []error
+	loaded := make(map[string]TrampolineSkill, len(CommonSkills)+len(entries))
+	for key, skill := range CommonSkills {
+		loaded[key] = skill
+	}
+	for key, skill := range entries {
+		if err := validateCatalogueEntry(&skill); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %q: %w", path, key, err))
+			continue
+		}
+		loaded[key] = skill
+	}
+	if len(problems) > 0 {
+		return nil, errors.Join(problems...)
+	}
+	return loaded, nil
+}
+
+func validateCatalogueEntry(skill *TrampolineSkill) error {
+	if skill.Name == "" {
+		return errors.New("missing name")
+	}
+	if skill.Rotation < 0 {
+		return errors.New("negative rotation")
+	}
+	// Unknown strings decode to the invalid values rather than failing
+	if skill.TakeoffPosition == Invalid {
+		return errors.New("unknown takeoff_position, want Feet, Front, Back or Seat")
+	}
+	if skill.Shape == InvalidShape {
+		return errors.New("unknown shape, want Straight, Tuck, Pike or Straddle")
+	}
+	if err := skill.Validate(); err != nil {
+		return fmt.Errorf("twist phases: %w", err)
+	}
+	if skill.LandingPosition() == Invalid {
+		return errors.New("invalid landing position")
+	}
+	return nil
+}
+
+// WatchCatalogue loads the catalogue file at path into the live catalogue and
+// then checks its modification time every interval, reloading it when it
+// changes. A file that fails to load leaves the previous catalogue in place.
+// The first load's error is returned; later errors are logged.
+func WatchCatalogue(path string, interval time.Duration) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	loaded, err := LoadCatalogue(path)
+	if err != nil {
+		return err
+	}
+	SetCatalogue(loaded)
+	log.Printf("Loaded %d common skills from %s", len(loaded), path)
+
+	go func() {
+		lastMod := info.ModTime()
+		for range time.Tick(interval) {
+			info, err := os.Stat(path)
+			if err != nil {
+				log.Printf("Error checking skill catalogue: %v", err)
+				continue
+			}
+			if info.ModTime().Equal(lastMod) {
+				continue
+			}
+			lastMod = info.ModTime()
+			loaded, err := LoadCatalogue(path)
+			if err != nil {
+				log.Printf("Error reloading skill catalogue, keeping previous: %v", err)
+				continue
+			}
+			SetCatalogue(loaded)
+			log.Printf("Reloaded %d common skills from %s", len(loaded), path)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,133 @@
+package skills
+
+import (
+	"fmt"
+	"strings"
+)
+
+// twistNames are the conventional names for the half twists in one
+// somersault, indexed by the number of half twists.
+var twistNames = []string{"", "Half", "Full", "Rudi", "Double-Full", "Randi", "Triple-Full", "Adolph"}
+
+// singleTwistNames replace "<Direction> <Twist>" for single forward
+// somersaults with an odd number of half twists.
+var singleTwistNames = map[int]string{1: "Barani", 3: "Rudi", 5: "Randi", 7: "Adolph"}
+
+var multiplePrefixes = map[int]string{8: "Double", 12: "Triple", 16: "Quadruple"}
+
+func twistName(halves int) string {
+	if halves < len(twistNames) {
+		return twistNames[halves]
+	}
+	return fmt.Sprintf("%d-Half", halves)
+}
+
+// DescriptiveName builds a conventional name for a whole somersault from feet
+// to feet, or returns "" for anything else. The rules are:
+//
+//   - Untwisted skills are the direction and shape: "Back Tuck", "Double Front Pike".
+//   - Single somersaults name the twist: forward odd twists are "Barani",
+//     "Rudi", "Randi" and "Adolph"; others are "Full Back", "Front Full",
+//     "Back Rudi".
+//   - Multiple somersaults name the first somersault's twist "-In" and the
+//     last's "-Out": "Full-In Half-Out", "Half-In Rudi-Out". An untwisted
+//     last somersault is "Back-Out" or "Front-Out" by the way the athlete is
+//     rotating after the twists before it; an untwisted first somersault is
+//     "Back-In" when backward and left out when forward ("Half-Out").
+//     Twisting middle somersaults of triples and quadruples are "-Mid".
+//   - Triples and quadruples are prefixed "Triple" or "Quadruple", except a
+//     forward triple with a single half twist, which is a "Triffis" ("Half-In
+//     Triffis" or "Half-Out Triffis" when the twist is not in the middle).
+//   - The shape is added when it distinguishes the skill: always for
+//     multiple somersaults and for singles with less than a full twist.
+func DescriptiveName(skill *TrampolineSkill) string {
+	if skill.TakeoffPosition != Feet || skill.SeatLanding || skill.Rotation <= 0 || skill.Rotation%4 != 0 {
+		return ""
+	}
+	if len(skill.TwistDistribution) != CalculatePhases(skill.Rotation) {
+		return ""
+	}
+	prefix, ok := multiplePrefixes[skill.Rotation]
+	if !ok && skill.Rotation != 4 {
+		return ""
+	}
+
+	direction := "Front"
+	if skill.Backward {
+		direction = "Back"
+	}
+	totalTwist := skill.TotalTwist()
+	var name string
+
+	switch {
+	case skill.Rotation == 4:
+		if totalTwist == 0 {
+			name = direction
+		} else if single, ok := singleTwistNames[totalTwist]; ok && !skill.Backward {
+			name = single
+		} else if skill.Backward && totalTwist%2 == 0 {
+			name = twistName(totalTwist) + " Back"
+		} else {
+			name = direction + " " + twistName(totalTwist)
+		}
+	case totalTwist == 0:
+		name = prefix + " " + direction
+	case skill.Rotation == 12 && !skill.Backward && totalTwist == 1:
+		switch {
+		case skill.TwistDistribution[0] == 1:
+			name = "Half-In Triffis"
+		case skill.TwistDistribution[2] == 1:
+			name = "Half-Out Triffis"
+		default:
+			name = "Triffis"
+		}
+	default:
+		name = multipleSomersaultTwists(skill)
+		if skill.Rotation > 8 {
+			name = prefix + " " + name
+		}
+	}
+
+	if skill.Rotation >= 6 || totalTwist < 2 {
+		name += " " + skill.Shape.String()
+	}
+	return name
+}
+
+// multipleSomersaultTwists names the in, middle and out twists of a twisting
+// multiple somersault, e.g. "Full-In Back-Out".
+func multipleSomersaultTwists(skill *TrampolineSkill) string {
+	twists := skill.TwistDistribution
+	last := len(twists) - 1
+	var parts []string
+
+	switch {
+	case twists[0] > 0:
+		parts = append(parts, twistName(twists[0])+"-In")
+	case skill.Backward:
+		parts = append(parts, "Back-In")
+	}
+	for _, halves := range twists[1:last] {
+		if halves > 0 {
+			parts = append(parts, twistName(halves)+"-Mid")
+		}
+	}
+	if twists[last] > 0 {
+		parts = append(parts, twistName(twists[last])+"-Out")
+	} else {
+		// Each odd half twist turns the athlete round, so a forward somersault
+		// is performed as a back somersault after it and vice versa.
+		backward := skill.Backward
+		for _, halves := range twists[:last] {
+			if halves%2 == 1 {
+				backward = !backward
+			}
+		}
+		if backward {
+			parts = append(parts, "Back-Out")
+		} else {
+			parts = append(parts, "Front-Out")
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,54 @@
+package skills
+
+import "testing"
+
+func TestDescriptiveName(t *testing.T) {
+	tests := []struct {
+		rotation int
+		twists   []int
+		backward bool
+		shape    Shape
+		want     string
+	}{
+		{4, []int{0}, true, Tuck, "Back Tuck"},
+		{4, []int{0}, false, Pike, "Front Pike"},
+		{4, []int{1}, false, Straight, "Barani Straight"},
+		{4, []int{2}, true, Straight, "Full Back"},
+		{4, []int{2}, false, Straight, "Front Full"},
+		{4, []int{3}, false, Straight, "Rudi"},
+		{4, []int{3}, true, Straight, "Back Rudi"},
+		{8, []int{0, 0}, true, Tuck, "Double Back Tuck"},
+		{8, []int{2, 1}, true, Straight, "Full-In Half-Out Straight"},
+		{8, []int{2, 0}, true, Tuck, "Full-In Back-Out Tuck"},
+		{8, []int{1, 0}, false, Tuck, "Half-In Back-Out Tuck"},
+		{8, []int{0, 3}, false, Pike, "Rudi-Out Pike"},
+		{8, []int{0, 3}, true, Tuck, "Back-In Rudi-Out Tuck"},
+		{12, []int{0, 0, 0}, true, Tuck, "Triple Back Tuck"},
+		{12, []int{0, 1, 0}, false, Pike, "Triffis Pike"},
+		{12, []int{1, 0, 0}, false, Tuck, "Half-In Triffis Tuck"},
+		{12, []int{0, 0, 1}, false, Tuck, "Half-Out Triffis Tuck"},
+		{12, []int{2, 0, 2}, true, Tuck, "Triple Full-In Full-Out Tuck"},
+		{12, []int{2, 1, 2}, true, Straight, "Triple Full-In Half-Mid Full-Out Straight"},
+		{16, []int{0, 0, 0, 0}, true, Tuck, "Quadruple Back Tuck"},
+		{6, []int{0}, true, Tuck, ""},  // not a whole somersault
+		{8, []int{2}, true, Tuck, ""},  // wrong number of twist phases
+		{0, []int{2}, false, Tuck, ""}, // no somersault
+	}
+	for _, test := range tests {
+		skill := TrampolineSkill{Rotation: test.rotation, TwistDistribution: test.twists, Backward: test.backward, Shape: test.shape, TakeoffPosition: Feet}
+		if got := DescriptiveName(&skill); got != test.want {
+			t.Errorf("DescriptiveName(%s, backward %v) = %q, want %q", skill.FIGNotation(), test.backward, got, test.want)
+		}
+	}
+}
+
+func TestDescriptiveNameNotFromFeet(t *testing.T) {
+	for _, skill := range []TrampolineSkill{
+		{Rotation: 4, TwistDistribution: []int{0}, TakeoffPosition: Back, Shape: Tuck},
+		{Rotation: 4, TwistDistribution: []int{0}, TakeoffPosition: Feet, Shape: Tuck, SeatLanding: true},
+	} {
+		if got := DescriptiveName(&skill); got != "" {
+			t.Errorf("DescriptiveName(%+v) = %q, want \"\"", skill, got)
+		}
+	}
+}
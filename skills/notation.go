@@ -0,0 +1,172 @@
+package skills
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NotationError describes why a FIG notation string could not be parsed.
+type NotationError struct {
+	Notation string
+	Offset   int // Byte offset in Notation where the problem is
+	Phase    int // 1-based twist phase the error refers to, 0 for the rotation or the whole notation
+	Msg      string
+}
+
+func (e *NotationError) Error() string {
+	if e.Phase > 0 {
+		return fmt.Sprintf("invalid FIG notation %q at offset %d: phase %d: %s", e.Notation, e.Offset, e.Phase, e.Msg)
+	}
+	return fmt.Sprintf("invalid FIG notation %q at offset %d: %s", e.Notation, e.Offset, e.Msg)
+}
+
+// ParseFIGNotation turns a FIG notation string such as "(8 - 1 <)" back into a
+// TrampolineSkill. It accepts the output of FIGNotation as well as common
+// variants: missing parentheses, "0" instead of "-", commas as separators and
+// tokens written without spaces ("8-1<"). Errors are *NotationError.
+//
+// FIG notation does not record direction, takeoff position or seat landings,
+// so the returned skill is a forward skill from feet; callers that know better
+// should set those fields afterwards. A missing shape symbol means Straight.
+func ParseFIGNotation(notation string) (TrampolineSkill, error) {
+	fail := func(offset, phase int, format string, args ...interface{}) (TrampolineSkill, error) {
+		return TrampolineSkill{}, &NotationError{Notation: notation, Offset: offset, Phase: phase, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	start := len(notation) - len(strings.TrimLeftFunc(notation, unicode.IsSpace))
+	end := len(strings.TrimRightFunc(notation, unicode.IsSpace))
+	hasOpen := strings.HasPrefix(notation[start:end], "(")
+	hasClose := strings.HasSuffix(notation[start:end], ")")
+	switch {
+	case hasOpen && !hasClose:
+		return fail(end, 0, "missing closing parenthesis")
+	case hasClose && !hasOpen:
+		return fail(start, 0, "missing opening parenthesis")
+	case hasOpen:
+		start, end = start+1, end-1
+	}
+
+	tokens, err := tokenizeFIGNotation(notation, start, end)
+	if err != nil {
+		return TrampolineSkill{}, err
+	}
+
+	skill := TrampolineSkill{TakeoffPosition: Feet, Shape: Straight, TwistDistribution: []int{0}}
+
+	// "" and "()" are a straight jump, "(o)" a shape jump.
+	if len(tokens) == 0 {
+		return skill, nil
+	}
+	first := tokens[0]
+	if first.shape != InvalidShape {
+		if len(tokens) > 1 {
+			return fail(tokens[1].offset, 0, "unexpected %q after shape symbol", tokens[1].text)
+		}
+		skill.Shape = first.shape
+		return skill, nil
+	}
+
+	if first.text == "-" {
+		return fail(first.offset, 0, "rotation must be a number of quarter somersaults, got \"-\"")
+	}
+	if first.value > 16 {
+		return fail(first.offset, 0, "rotation must be between 0 and 16 quarter somersaults, got %d", first.value)
+	}
+	skill.Rotation = first.value
+
+	phases := CalculatePhases(skill.Rotation)
+	rest := tokens[1:]
+	if n := len(rest); n > 0 && rest[n-1].shape != InvalidShape {
+		skill.Shape = rest[n-1].shape
+		rest = rest[:n-1]
+	}
+	for _, token := range rest {
+		if token.shape != InvalidShape {
+			return fail(token.offset, token.phase, "shape symbol %q must come after the last twist phase", token.text)
+		}
+	}
+	if len(rest) > phases {
+		return fail(rest[phases].offset, phases+1, "expected %d twist phases for %d/4 rotation, got %d", phases, skill.Rotation, len(rest))
+	}
+	if len(rest) < phases {
+		return fail(end, len(rest)+1, "expected %d twist phases for %d/4 rotation, got %d", phases, skill.Rotation, len(rest))
+	}
+
+	skill.TwistDistribution = make([]int, phases)
+	for i, token := range rest {
+		skill.TwistDistribution[i] = token.value
+	}
+	return skill, nil
+}
+
+// notationToken is a number, "-" or shape symbol of a FIG notation.
+type notationToken struct {
+	text   string
+	offset int   // Byte offset in the notation
+	phase  int   // 1-based twist phase the token is in, 0 for the rotation
+	value  int   // Quarter somersaults or half twists; 0 for "-" and shape symbols
+	shape  Shape // Shape of a shape symbol, InvalidShape for other tokens
+}
+
+// tokenizeFIGNotation splits notation[start:end], the inside of the
+// parentheses, into numbers, "-" and shape symbols. Whitespace and commas are
+// separators. The rotation is phase 0 and each number or "-" after it starts
+// the next phase; a shape symbol is in the phase after the last number.
+func tokenizeFIGNotation(notation string, start, end int) ([]notationToken, error) {
+	var tokens []notationToken
+	phase := 0
+	for i := start; i < end; {
+		r, size := utf8.DecodeRuneInString(notation[i:end])
+		token := notationToken{text: notation[i : i+size], offset: i, phase: phase, shape: InvalidShape}
+		switch {
+		case unicode.IsSpace(r) || r == ',':
+			i += size
+			continue
+		case r >= '0' && r <= '9':
+			j := i
+			for j < end && notation[j] >= '0' && notation[j] <= '9' {
+				j++
+			}
+			token.text = notation[i:j]
+			value, err := strconv.Atoi(token.text)
+			if err != nil || value > maxNotationNumber {
+				return nil, &NotationError{Notation: notation, Offset: i, Phase: phase, Msg: fmt.Sprintf("number %s is too large", token.text)}
+			}
+			token.value = value
+			phase++
+		case r == '-':
+			phase++
+		default:
+			shape, ok := shapeFromSymbol(token.text)
+			if !ok {
+				return nil, &NotationError{Notation: notation, Offset: i, Phase: phase, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			token.shape = shape
+		}
+		tokens = append(tokens, token)
+		i += len(token.text)
+	}
+	return tokens, nil
+}
+
+// maxNotationNumber bounds rotations and twists well above anything
+// performed, so numbers never overflow.
+const maxNotationNumber = 99
+
+func shapeFromSymbol(symbol string) (Shape, bool) {
+	switch strings.ToLower(symbol) {
+	case "o":
+		return Tuck, true
+	case "<":
+		return Pike, true
+	case "/":
+		return Straight, true
+	case "v":
+		return Straddle, true
+	default:
+		return InvalidShape, false
+	}
+}
@@ -0,0 +1,100 @@
+package skills
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseFIGNotation(t *testing.T) {
+	tests := []struct {
+		notation string
+		rotation int
+		twists   []int
+		shape    Shape
+	}{
+		{"(8 - 1 <)", 8, []int{0, 1}, Pike},
+		{"8 - 1 <", 8, []int{0, 1}, Pike},
+		{"8-1<", 8, []int{0, 1}, Pike},
+		{"(8, 0, 1, <)", 8, []int{0, 1}, Pike},
+		{"( 4 0 o )", 4, []int{0}, Tuck},
+		{"(4 2 /)", 4, []int{2}, Straight},
+		{"(4 2)", 4, []int{2}, Straight},
+		{"(12 1 - 1 /)", 12, []int{1, 0, 1}, Straight},
+		{"(5 1 /)", 5, []int{1}, Straight},
+		{"(0 1)", 0, []int{1}, Straight},
+		{"(O)", 0, []int{0}, Tuck},
+		{"(v)", 0, []int{0}, Straddle},
+		{"()", 0, []int{0}, Straight},
+		{"", 0, []int{0}, Straight},
+	}
+	for _, test := range tests {
+		skill, err := ParseFIGNotation(test.notation)
+		if err != nil {
+			t.Errorf("ParseFIGNotation(%q) error: %v", test.notation, err)
+			continue
+		}
+		if skill.Rotation != test.rotation || !slices.Equal(skill.TwistDistribution, test.twists) || skill.Shape != test.shape {
+			t.Errorf("ParseFIGNotation(%q) = rotation %d, twists %v, shape %v; want %d, %v, %v",
+				test.notation, skill.Rotation, skill.TwistDistribution, skill.Shape, test.rotation, test.twists, test.shape)
+		}
+		if skill.TakeoffPosition != Feet || skill.Backward || skill.SeatLanding {
+			t.Errorf("ParseFIGNotation(%q) = takeoff %v, backward %v, seat landing %v; want a forward skill from feet",
+				test.notation, skill.TakeoffPosition, skill.Backward, skill.SeatLanding)
+		}
+	}
+}
+
+func TestParseFIGNotationErrors(t *testing.T) {
+	tests := []struct {
+		notation string
+		offset   int
+		phase    int
+	}{
+		{"(8 - 1 <", 8, 0},                     // missing closing parenthesis, at the end
+		{"8 - 1 <)", 0, 0},                     // missing opening parenthesis
+		{"(8 - x <)", 5, 2},                    // unknown character in the second phase
+		{"(x)", 1, 0},                          // unknown character as the rotation
+		{"(8 - ١ <)", 5, 2},                    // non-ASCII digit
+		{"(8 1)", 4, 2},                        // second phase missing
+		{"(8 1 2 3)", 7, 3},                    // a third phase
+		{"(8 o 1)", 3, 1},                      // shape before the last phase
+		{"(o 1)", 3, 0},                        // phases after a shape jump
+		{"(- 1)", 1, 0},                        // "-" as the rotation
+		{"(20 - -)", 1, 0},                     // rotation out of range
+		{"(8 - 99999999999999999999 <)", 5, 2}, // twist too large to parse
+		{"  (8 - x)", 7, 2},                    // offsets count leading space
+	}
+	for _, test := range tests {
+		_, err := ParseFIGNotation(test.notation)
+		var notationErr *NotationError
+		if !errors.As(err, &notationErr) {
+			t.Errorf("ParseFIGNotation(%q) error = %v, want a *NotationError", test.notation, err)
+			continue
+		}
+		if notationErr.Offset != test.offset || notationErr.Phase != test.phase {
+			t.Errorf("ParseFIGNotation(%q) error at offset %d, phase %d; want offset %d, phase %d (%v)",
+				test.notation, notationErr.Offset, notationErr.Phase, test.offset, test.phase, err)
+		}
+		if notationErr.Notation != test.notation {
+			t.Errorf("ParseFIGNotation(%q) error notation = %q", test.notation, notationErr.Notation)
+		}
+	}
+}
+
+// TestParseFIGNotationRoundTrip parses the notation of every built-in skill
+// and checks it gives the same notation back.
+func TestParseFIGNotationRoundTrip(t *testing.T) {
+	for key, skill := range Catalogue() {
+		notation := skill.FIGNotation()
+		parsed, err := ParseFIGNotation(notation)
+		if err != nil {
+			t.Errorf("%s: ParseFIGNotation(%q) error: %v", key, notation, err)
+			continue
+		}
+		parsed.TakeoffPosition, parsed.Backward, parsed.SeatLanding = skill.TakeoffPosition, skill.Backward, skill.SeatLanding
+		if got := parsed.FIGNotation(); got != notation {
+			t.Errorf("%s: ParseFIGNotation(%q).FIGNotation() = %q", key, notation, got)
+		}
+	}
+}
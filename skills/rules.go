@@ -0,0 +1,239 @@
+package skills
+
+import (
+	"sort"
+	"sync"
+)
+
+// TariffRules calculates tariffs according to one code of points. Register
+// additional rule sets with RegisterTariffRules.
+type TariffRules interface {
+	ID() string   // Stable key used in requests, e.g. "fig-2025-2028"
+	Name() string // Human readable label, e.g. "FIG 2025–2028"
+	Tariff(skill *TrampolineSkill) float64
+	Breakdown(skill *TrampolineSkill) TariffBreakdown
+}
+
+// DefaultTariffRulesID is the rule set used when none is selected.
+const DefaultTariffRulesID = "fig-2025-2028"
+
+var (
+	tariffRulesMu sync.RWMutex
+	tariffRules   = map[string]TariffRules{}
+)
+
+func init() {
+	RegisterTariffRules(fig2022)
+	RegisterTariffRules(fig2025)
+}
+
+// RegisterTariffRules makes a rule set available under its ID, replacing any
+// rule set already registered with the same ID.
+func RegisterTariffRules(rules TariffRules) {
+	tariffRulesMu.Lock()
+	defer tariffRulesMu.Unlock()
+	tariffRules[rules.ID()] = rules
+}
+
+func GetTariffRules(id string) (TariffRules, bool) {
+	tariffRulesMu.RLock()
+	defer tariffRulesMu.RUnlock()
+	rules, exists := tariffRules[id]
+	return rules, exists
+}
+
+func DefaultTariffRules() TariffRules {
+	rules, _ := GetTariffRules(DefaultTariffRulesID)
+	return rules
+}
+
+// TariffRuleSets returns every registered rule set, newest cycle first.
+func TariffRuleSets() []TariffRules {
+	tariffRulesMu.RLock()
+	defer tariffRulesMu.RUnlock()
+	list := make([]TariffRules, 0, len(tariffRules))
+	for _, rules := range tariffRules {
+		list = append(list, rules)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID() > list[j].ID() })
+	return list
+}
+
+// multipleSomersaultBonus holds the extra tenths a code of points awards to
+// double, triple or quadruple somersaults on top of rotation and twist.
+type multipleSomersaultBonus struct {
+	Base           int // Awarded to every multiple somersault of this size
+	Backward       int // Added when the somersaults are backward
+	StraightPike   int // Added for straight or piked shape
+	TwistThreshold int // Half twists included before the surcharge applies
+	TwistSurcharge int // Added per half twist above TwistThreshold
+}
+
+// CodeOfPoints is a TariffRules implementation for the FIG trampoline code of
+// points. Cycles share the rotation and twist values and differ in the bonuses
+// for multiple somersaults.
+type CodeOfPoints struct {
+	id     string
+	name   string
+	double multipleSomersaultBonus
+	triple multipleSomersaultBonus
+	quad   multipleSomersaultBonus
+}
+
+// fig2025 is the 2025–2028 cycle, the FIG Trampoline Gymnastics Code of
+// Points 2025–2028. These are the values the calculator used before rule
+// sets could be selected.
+var fig2025 = &CodeOfPoints{
+	id:     "fig-2025-2028",
+	name:   "FIG 2025–2028",
+	double: multipleSomersaultBonus{Base: 2, Backward: 1, StraightPike: 2, TwistThreshold: 4, TwistSurcharge: 1},
+	triple: multipleSomersaultBonus{Base: 4, Backward: 2, StraightPike: 3, TwistThreshold: 2, TwistSurcharge: 2},
+	quad:   multipleSomersaultBonus{Base: 6, Backward: 3, StraightPike: 4, TwistThreshold: 0, TwistSurcharge: 2},
+}
+
+// fig2022 is the 2022–2024 cycle, the FIG Trampoline Gymnastics Code of
+// Points 2022–2024, for checking cards from competitions held under it. Its
+// difficulty values are those of 2025–2028 except that backward multiple
+// somersaults earn no bonus for their direction.
+var fig2022 = &CodeOfPoints{
+	id:     "fig-2022-2024",
+	name:   "FIG 2022–2024",
+	double: multipleSomersaultBonus{Base: 2, StraightPike: 2, TwistThreshold: 4, TwistSurcharge: 1},
+	triple: multipleSomersaultBonus{Base: 4, StraightPike: 3, TwistThreshold: 2, TwistSurcharge: 2},
+	quad:   multipleSomersaultBonus{Base: 6, StraightPike: 4, TwistThreshold: 0, TwistSurcharge: 2},
+}
+
+func (cop *CodeOfPoints) ID() string   { return cop.id }
+func (cop *CodeOfPoints) Name() string { return cop.name }
+
+func (cop *CodeOfPoints) Tariff(skill *TrampolineSkill) float64 {
+	return cop.Breakdown(skill).Total
+}
+
+func (cop *CodeOfPoints) Breakdown(skill *TrampolineSkill) TariffBreakdown {
+	var b BreakdownBuilder
+	switch {
+	case skill.Rotation == 0:
+		noSomersaultTariff(&b, skill)
+	case skill.Rotation < 8:
+		singleSomersaultTariff(&b, skill)
+	case skill.Rotation < 12:
+		multipleSomersaultTariff(&b, skill, "Double somersault", cop.double)
+	case skill.Rotation < 16:
+		multipleSomersaultTariff(&b, skill, "Triple somersault", cop.triple)
+	default:
+		multipleSomersaultTariff(&b, skill, "Quadruple somersault", cop.quad)
+	}
+	return b.Result()
+}
+
+// TariffComponent is one labelled contribution to a skill's tariff.
+type TariffComponent struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+}
+
+// TariffBreakdown explains a tariff line by line. Total is the sum of the
+// component values.
+type TariffBreakdown struct {
+	Components []TariffComponent `json:"components"`
+	Total      float64           `json:"total"`
+}
+
+// BreakdownBuilder collects components in tenths so totals stay exact. Other
+// disciplines' rules build their breakdowns with it too.
+type BreakdownBuilder struct {
+	components []TariffComponent
+	tenths     int
+}
+
+// Add appends a component worth tenths, unless it is worth nothing.
+func (b *BreakdownBuilder) Add(label string, tenths int) {
+	if tenths == 0 {
+		return
+	}
+	b.components = append(b.components, TariffComponent{Label: label, Value: float64(tenths) / 10})
+	b.tenths += tenths
+}
+
+func (b *BreakdownBuilder) Result() TariffBreakdown {
+	components := b.components
+	if components == nil {
+		components = []TariffComponent{}
+	}
+	return TariffBreakdown{Components: components, Total: float64(b.tenths) / 10}
+}
+
+// SomersaultValues are the tariff values, in tenths, for skills with one
+// number of completed somersaults, in disciplines whose code of points
+// tabulates them per quarter somersault and half twist.
+type SomersaultValues struct {
+	Label         string
+	PerQuarter    int  // Per 1/4 somersault
+	PerHalfTwist  int  // Per 1/2 twist
+	Completion    int  // Bonus for completing the somersaults
+	StraightPike  int  // Bonus for straight or piked shape
+	UntwistedOnly bool // StraightPike only applies without twist
+}
+
+// SomersaultTable lists SomersaultValues by number of completed somersaults,
+// from none. Skills with more somersaults than the table use its last row.
+type SomersaultTable []SomersaultValues
+
+// Values returns the row for the skill's completed somersaults.
+func (table SomersaultTable) Values(skill *TrampolineSkill) SomersaultValues {
+	return table[min(skill.Rotation/4, len(table)-1)]
+}
+
+// Add adds the skill's rotation, twist, completion and shape values to b.
+func (table SomersaultTable) Add(b *BreakdownBuilder, skill *TrampolineSkill) {
+	values := table.Values(skill)
+	b.Add("Rotation", skill.Rotation*values.PerQuarter)
+	b.Add("Twist", skill.TotalTwist()*values.PerHalfTwist)
+	b.Add(values.Label+" bonus", values.Completion)
+	if (skill.Shape == Straight || skill.Shape == Pike) && (!values.UntwistedOnly || skill.TotalTwist() == 0) {
+		b.Add("Straight/pike bonus", values.StraightPike)
+	}
+}
+
+func noSomersaultTariff(b *BreakdownBuilder, skill *TrampolineSkill) {
+	if skill.TotalTwist() != 0 {
+		b.Add("Twist", skill.TotalTwist())
+		return
+	}
+	if skill.Shape != Straight {
+		b.Add("Shape jump", 1)
+		return
+	}
+	if (skill.TakeoffPosition != Seat && skill.SeatLanding) || (skill.TakeoffPosition == Seat && !skill.SeatLanding) {
+		b.Add("Seat landing/takeoff", 1)
+	}
+}
+func singleSomersaultTariff(b *BreakdownBuilder, skill *TrampolineSkill) {
+	b.Add("Rotation", skill.Rotation)
+	if skill.Rotation > 3 {
+		b.Add("Completed somersault", 1)
+		if skill.TotalTwist() == 0 {
+			switch skill.Shape {
+			case Straight, Pike:
+				b.Add("Straight/pike bonus", 1)
+			default:
+			}
+		}
+	}
+	b.Add("Twist", skill.TotalTwist())
+}
+func multipleSomersaultTariff(b *BreakdownBuilder, skill *TrampolineSkill, label string, bonus multipleSomersaultBonus) {
+	b.Add("Rotation", skill.Rotation)
+	b.Add("Twist", skill.TotalTwist())
+	b.Add(label+" bonus", bonus.Base)
+	if skill.Backward {
+		b.Add("Backward bonus", bonus.Backward)
+	}
+	if skill.Shape == Straight || skill.Shape == Pike {
+		b.Add("Straight/pike bonus", bonus.StraightPike)
+	}
+	if skill.TotalTwist() > bonus.TwistThreshold {
+		b.Add("Multi-twist surcharge", (skill.TotalTwist()-bonus.TwistThreshold)*bonus.TwistSurcharge)
+	}
+}
@@ -0,0 +1,34 @@
+package skills
+
+import "testing"
+
+func TestCodeOfPointsCycles(t *testing.T) {
+	tests := []struct {
+		skill   string
+		fig2022 float64
+		fig2025 float64
+	}{
+		{"barani", 0.6, 0.6},
+		{"backSomersault", 0.5, 0.5},
+		{"doubleBack", 1.0, 1.1},
+		{"fullFull", 1.6, 1.7},
+		{"miller", 2.0, 2.1},
+		{"tripleBack", 1.6, 1.8},
+		{"halfOut", 1.1, 1.1}, // Forward, the same in both cycles
+	}
+	for _, test := range tests {
+		skill := CommonSkills[test.skill]
+		for _, cycle := range []struct {
+			id   string
+			want float64
+		}{{"fig-2022-2024", test.fig2022}, {"fig-2025-2028", test.fig2025}} {
+			rules, ok := GetTariffRules(cycle.id)
+			if !ok {
+				t.Fatalf("%s not registered", cycle.id)
+			}
+			if got := rules.Tariff(&skill); got != cycle.want {
+				t.Errorf("%s tariff under %s = %.1f, want %.1f", test.skill, cycle.id, got, cycle.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package skills
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Share codes pack a routine into a short URL-safe string. After a version
+// byte each skill is
+//
+//	byte 0: rotation in 1/4 somersaults (bits 0-5), backward (bit 6), seat landing (bit 7)
+//	byte 1: shape (bits 0-1), takeoff position (bits 2-3), bits 4-7 zero
+//
+// followed by one byte of half twists for each of CalculatePhases(rotation)
+// phases, all base64url encoded without padding. A back tuck is six
+// characters and a ten-skill routine usually under sixty.
+const (
+	shareCodeVersion = 1
+
+	MaxShareRotation   = MaxRotation
+	MaxShareSkills     = 20
+	maxShareTwist      = 255
+	shareBackwardBit   = 1 << 6
+	shareSeatBit       = 1 << 7
+	shareRotationMask  = 0x3f
+	shareShapeMask     = 0x03
+	shareTakeoffShift  = 2
+	shareTakeoffMask   = 0x03
+	shareReservedMask  = 0xf0
+	shareSkillByteSize = 2
+)
+
+// EncodeRoutine returns the share code for a routine. Twist phases are
+// trimmed or zero-padded to the rotation's phase count first.
+func EncodeRoutine(routine []TrampolineSkill) (string, error) {
+	if len(routine) > MaxShareSkills {
+		return "", fmt.Errorf("routine has %d skills, share codes hold at most %d", len(routine), MaxShareSkills)
+	}
+	data := []byte{shareCodeVersion}
+	for i, skill := range routine {
+		if skill.Rotation < 0 || skill.Rotation > MaxShareRotation {
+			return "", fmt.Errorf("skill %d: rotation %d outside 0-%d", i+1, skill.Rotation, MaxShareRotation)
+		}
+		if skill.Shape < Straight || skill.Shape > Straddle {
+			return "", fmt.Errorf("skill %d: invalid shape", i+1)
+		}
+		if skill.TakeoffPosition < Feet || skill.TakeoffPosition > Seat {
+			return "", fmt.Errorf("skill %d: invalid takeoff position", i+1)
+		}
+		first := byte(skill.Rotation)
+		if skill.Backward {
+			first |= shareBackwardBit
+		}
+		if skill.SeatLanding {
+			first |= shareSeatBit
+		}
+		data = append(data, first, byte(skill.Shape)|byte(skill.TakeoffPosition)<<shareTakeoffShift)
+		for phase := 0; phase < CalculatePhases(skill.Rotation); phase++ {
+			twist := 0
+			if phase < len(skill.TwistDistribution) {
+				twist = skill.TwistDistribution[phase]
+			}
+			if twist < 0 || twist > maxShareTwist {
+				return "", fmt.Errorf("skill %d: twist %d outside 0-%d half twists", i+1, twist, maxShareTwist)
+			}
+			data = append(data, byte(twist))
+		}
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeRoutine parses a share code from EncodeRoutine. Skill names and
+// tariffs are left for the caller to fill in.
+func DecodeRoutine(code string) ([]TrampolineSkill, error) {
+	data, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return nil, fmt.Errorf("invalid share code: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("invalid share code: empty")
+	}
+	if data[0] != shareCodeVersion {
+		return nil, fmt.Errorf("invalid share code: unknown version %d", data[0])
+	}
+	routine := []TrampolineSkill{}
+	for i := 1; i < len(data); {
+		if len(routine) == MaxShareSkills {
+			return nil, fmt.Errorf("invalid share code: more than %d skills", MaxShareSkills)
+		}
+		if i+shareSkillByteSize > len(data) {
+			return nil, fmt.Errorf("invalid share code: skill %d truncated", len(routine)+1)
+		}
+		first, second := data[i], data[i+1]
+		i += shareSkillByteSize
+		skill := TrampolineSkill{
+			Rotation:        int(first & shareRotationMask),
+			Backward:        first&shareBackwardBit != 0,
+			SeatLanding:     first&shareSeatBit != 0,
+			Shape:           Shape(second & shareShapeMask),
+			TakeoffPosition: BodyPosition(second >> shareTakeoffShift & shareTakeoffMask),
+		}
+		if skill.Rotation > MaxShareRotation {
+			return nil, fmt.Errorf("invalid share code: skill %d rotation %d", len(routine)+1, skill.Rotation)
+		}
+		if second&shareReservedMask != 0 {
+			return nil, fmt.Errorf("invalid share code: skill %d has unknown flags", len(routine)+1)
+		}
+		phases := CalculatePhases(skill.Rotation)
+		if i+phases > len(data) {
+			return nil, fmt.Errorf("invalid share code: skill %d twists truncated", len(routine)+1)
+		}
+		skill.TwistDistribution = make([]int, phases)
+		for phase := range skill.TwistDistribution {
+			skill.TwistDistribution[phase] = int(data[i+phase])
+		}
+		i += phases
+		routine = append(routine, skill)
+	}
+	return routine, nil
+}
@@ -0,0 +1,120 @@
+package skills
+
+import (
+	"encoding/base64"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// shareCode base64url encodes raw share code bytes.
+func shareCode(data ...byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestEncodeRoutine(t *testing.T) {
+	tests := []struct {
+		routine []TrampolineSkill
+		want    string
+	}{
+		{[]TrampolineSkill{}, shareCode(1)},
+		{[]TrampolineSkill{{Rotation: 4, Backward: true, Shape: Tuck, TwistDistribution: []int{0}}}, shareCode(1, 0x44, 0x01, 0)},
+		{[]TrampolineSkill{{Rotation: 1, TakeoffPosition: Back, Shape: Straight}}, shareCode(1, 0x01, 0x08, 0)},
+		{[]TrampolineSkill{{Rotation: 5, SeatLanding: true, TakeoffPosition: Seat, Shape: Straddle, TwistDistribution: []int{1, 9}}}, shareCode(1, 0x85, 0x0f, 1)},
+		{[]TrampolineSkill{{Rotation: 8, Shape: Pike, TwistDistribution: []int{0, 1}}, {Rotation: 0, Shape: Straight}}, shareCode(1, 0x08, 0x02, 0, 1, 0x00, 0x00, 0)},
+	}
+	for _, test := range tests {
+		got, err := EncodeRoutine(test.routine)
+		if err != nil {
+			t.Errorf("EncodeRoutine(%v) error: %v", test.routine, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("EncodeRoutine(%v) = %q, want %q", test.routine, got, test.want)
+		}
+	}
+}
+
+func TestEncodeRoutineErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		routine []TrampolineSkill
+	}{
+		{"too many skills", make([]TrampolineSkill, MaxShareSkills+1)},
+		{"negative rotation", []TrampolineSkill{{Rotation: -4}}},
+		{"rotation too large", []TrampolineSkill{{Rotation: MaxShareRotation + 1}}},
+		{"invalid shape", []TrampolineSkill{{Rotation: 4, Shape: InvalidShape}}},
+		{"invalid takeoff", []TrampolineSkill{{Rotation: 4, TakeoffPosition: Invalid}}},
+		{"negative twist", []TrampolineSkill{{Rotation: 4, TwistDistribution: []int{-1}}}},
+		{"twist too large", []TrampolineSkill{{Rotation: 4, TwistDistribution: []int{256}}}},
+	}
+	for _, test := range tests {
+		if code, err := EncodeRoutine(test.routine); err == nil {
+			t.Errorf("%s: EncodeRoutine = %q, want an error", test.name, code)
+		}
+	}
+}
+
+// TestShareCodeRoundTrip encodes the whole catalogue, a share code's worth
+// of skills at a time, and decodes it again.
+func TestShareCodeRoundTrip(t *testing.T) {
+	catalogue := Catalogue()
+	keys := make([]string, 0, len(catalogue))
+	for key := range catalogue {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for start := 0; start < len(keys); start += MaxShareSkills {
+		chunk := keys[start:min(start+MaxShareSkills, len(keys))]
+		routine := make([]TrampolineSkill, len(chunk))
+		for i, key := range chunk {
+			routine[i] = catalogue[key]
+		}
+		code, err := EncodeRoutine(routine)
+		if err != nil {
+			t.Fatalf("EncodeRoutine(%v) error: %v", chunk, err)
+		}
+		decoded, err := DecodeRoutine(code)
+		if err != nil {
+			t.Fatalf("DecodeRoutine(%q) error: %v", code, err)
+		}
+		if len(decoded) != len(routine) {
+			t.Fatalf("DecodeRoutine(%q) has %d skills, want %d", code, len(decoded), len(routine))
+		}
+		for i, skill := range routine {
+			twists := make([]int, CalculatePhases(skill.Rotation))
+			copy(twists, skill.TwistDistribution)
+			got := decoded[i]
+			if got.Rotation != skill.Rotation || got.Backward != skill.Backward || got.SeatLanding != skill.SeatLanding ||
+				got.Shape != skill.Shape || got.TakeoffPosition != skill.TakeoffPosition || !slices.Equal(got.TwistDistribution, twists) {
+				t.Errorf("%s: decoded as %s, want %s", chunk[i], got.FIGNotation(), skill.FIGNotation())
+			}
+		}
+	}
+}
+
+func TestDecodeRoutineErrors(t *testing.T) {
+	tooMany := []byte{1}
+	for range MaxShareSkills + 1 {
+		tooMany = append(tooMany, 0x00, 0x00, 0)
+	}
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"not base64url", "AU+A"},
+		{"padded", "AQ=="},
+		{"empty", ""},
+		{"unknown version", shareCode(2)},
+		{"skill truncated", shareCode(1, 0x44)},
+		{"twists truncated", shareCode(1, 0x08, 0x02, 0)},
+		{"rotation too large", shareCode(1, MaxShareRotation+1, 0x00, 0, 0, 0, 0, 0)},
+		{"unknown flags", shareCode(1, 0x44, 0x11, 0)},
+		{"too many skills", shareCode(tooMany...)},
+	}
+	for _, test := range tests {
+		if routine, err := DecodeRoutine(test.code); err == nil {
+			t.Errorf("%s: DecodeRoutine(%q) = %v, want an error", test.name, test.code, routine)
+		}
+	}
+}
@@ -52,92 +52,24 @@ func (skill *TrampolineSkill) LandingPosition() BodyPosition {
 	return positionByRotation
 }
 
-func (skill *TrampolineSkill) SetTariff() float64 {
-	tariff := 0.0
-	switch {
-	case skill.Rotation == 0:
-		tariff = noSomersaultTariff(skill)
-	case skill.Rotation < 8:
-		tariff = singleSomersaultTariff(skill)
-	case skill.Rotation < 12:
-		tariff = doubleSomersaultTariff(skill)
-	case skill.Rotation < 16:
-		tariff = tripleSomersaultTariff(skill)
-	default:
-		tariff = quadSomersaultTariff(skill)
+// SetTariff calculates the skill's tariff under the given rule set, stores it
+// on the skill and returns it. A nil rule set uses DefaultTariffRules.
+func (skill *TrampolineSkill) SetTariff(rules TariffRules) float64 {
+	if rules == nil {
+		rules = DefaultTariffRules()
 	}
+	tariff := rules.Tariff(skill)
 	skill.Tariff = tariff
 	return tariff
 }
-func noSomersaultTariff(skill *TrampolineSkill) float64 {
-	if skill.TotalTwist() != 0 {
-		return float64(skill.TotalTwist()) / 10
-	}
-	if skill.Shape != Straight {
-		return 0.1
-	}
-	if (skill.TakeoffPosition != Seat && skill.SeatLanding) || (skill.TakeoffPosition == Seat && !skill.SeatLanding) {
-		return 0.1
-	}
-	return 0
-}
-func singleSomersaultTariff(skill *TrampolineSkill) float64 {
-	tariff := 0
-	if skill.Rotation > 3 {
-		tariff++
-		if skill.TotalTwist() == 0 {
-			switch skill.Shape {
-			case Straight, Pike:
-				tariff++
-			default:
-			}
-		}
-	}
-	tariff += skill.Rotation
-	tariff += skill.TotalTwist()
-	return float64(tariff) / 10
-}
-func doubleSomersaultTariff(skill *TrampolineSkill) float64 {
-	tariff := 2
-	if skill.Backward {
-		tariff++
-	}
-	if skill.Shape == Straight || skill.Shape == Pike {
-		tariff += 2
-	}
-	if skill.TotalTwist() > 4 {
-		tariff += skill.TotalTwist() - 4
-	}
-	tariff += skill.Rotation
-	tariff += skill.TotalTwist()
-	return float64(tariff) / 10
-}
-func tripleSomersaultTariff(skill *TrampolineSkill) float64 {
-	tariff := 4
-	if skill.Backward {
-		tariff += 2
-	}
-	if skill.Shape == Straight || skill.Shape == Pike {
-		tariff += 3
-	}
-	if skill.TotalTwist() > 2 {
-		tariff += (skill.TotalTwist() - 2) * 2
-	}
-	tariff += skill.Rotation
-	tariff += skill.TotalTwist()
-	return float64(tariff) / 10
-}
-func quadSomersaultTariff(skill *TrampolineSkill) float64 {
-	tariff := 6
-	if skill.Backward {
-		tariff += 3
-	}
-	if skill.Shape == Straight || skill.Shape == Pike {
-		tariff += 4
+
+// TariffBreakdown lists the components that make up the skill's tariff under
+// the given rule set. A nil rule set uses DefaultTariffRules.
+func (skill *TrampolineSkill) TariffBreakdown(rules TariffRules) TariffBreakdown {
+	if rules == nil {
+		rules = DefaultTariffRules()
 	}
-	tariff += skill.Rotation
-	tariff += skill.TotalTwist() * 3
-	return float64(tariff) / 10
+	return rules.Breakdown(skill)
 }
 
 type BodyPosition int
@@ -301,6 +233,8 @@ func (skill *TrampolineSkill) Equal(b *TrampolineSkill) bool {
 
 }
 
+// CommonSkills is the built-in common skill catalogue. Read the live
+// catalogue, which may add skills from a file, with Catalogue.
 var CommonSkills = map[string]TrampolineSkill{
 	"shapeJump":       {Name: "Shape Jump", SeatLanding: false, Shape: Tuck, Backward: false, Rotation: 0, TwistDistribution: []int{0}, TakeoffPosition: Feet},
 	"halfTwist":       {Name: "Half Twist", SeatLanding: false, Shape: Straight, Backward: false, Rotation: 0, TwistDistribution: []int{1}, TakeoffPosition: Feet},
@@ -343,9 +277,14 @@ var CommonSkills = map[string]TrampolineSkill{
 }
 
 func GetCommonSkill(name string) (TrampolineSkill, bool) {
-	skill, exists := CommonSkills[name]
+	skill, exists := Catalogue()[name]
 	return skill, exists
 }
+
+// MaxRotation is the most quarter somersaults in a skill, a quadruple
+// somersault. The tariff tables and twist phases stop there.
+const MaxRotation = 16
+
 func CalculatePhases(rotation int) int {
 	absRotation := rotation
 	if absRotation < 0 {
@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tariffCalculator/skills"
+)
+
+// ErrNotFound is returned by Get and Delete for a name with no routine.
+var ErrNotFound = errors.New("routine not found")
+
+// Routine is a named routine belonging to Owner, the username of the
+// athlete or coach who saved it.
+type Routine struct {
+	Owner   string                   `json:"owner"`
+	Name    string                   `json:"name"`
+	Skills  []skills.TrampolineSkill `json:"skills"`
+	Notes   []Note                   `json:"notes"`
+	Updated time.Time                `json:"updated"`
+}
+
+// Note is a comment on a routine, usually from the owner's coach.
+type Note struct {
+	Author  string    `json:"author"`
+	Skill   int       `json:"skill"` // 1-based skill the note is about, 0 for the whole routine
+	Text    string    `json:"text"`
+	Created time.Time `json:"created"`
+}
+
+// Store keeps each owner's named routines. Saving a routine under an
+// existing name replaces it. Implementations are safe for concurrent use.
+type Store interface {
+	List(owner string) ([]Routine, error) // Sorted by name
+	Get(owner, name string) (Routine, error)
+	Put(routine Routine) error
+	Delete(owner, name string) error
+	// Update calls update with the routine, or a new one with only Owner
+	// and Name set if exists is false, and saves it unless update returns
+	// an error. No other change to the routine is made in between.
+	Update(owner, name string, update func(routine *Routine, exists bool) error) error
+}
+
+// ErrRenamed is returned by Update if update changes the Owner or Name.
+var ErrRenamed = errors.New("routine owner or name changed by update")
+
+// MaxNameLength is the longest routine name a store accepts, in bytes.
+const MaxNameLength = 100
+
+// ValidateName rejects names a store cannot keep.
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("missing routine name")
+	}
+	if len(name) > MaxNameLength {
+		return fmt.Errorf("routine name longer than %d bytes", MaxNameLength)
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return errors.New("routine name contains control characters")
+		}
+	}
+	return nil
+}
+
+// --- Memory Store ---
+
+// MemoryStore keeps routines until the server stops.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	routines map[string]map[string]Routine // By owner, then name
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{routines: map[string]map[string]Routine{}}
+}
+
+func (store *MemoryStore) List(owner string) ([]Routine, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	list := make([]Routine, 0, len(store.routines[owner]))
+	for _, routine := range store.routines[owner] {
+		list = append(list, routine)
+	}
+	sortByName(list)
+	return list, nil
+}
+
+func (store *MemoryStore) Get(owner, name string) (Routine, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	routine, exists := store.routines[owner][name]
+	if !exists {
+		return Routine{}, ErrNotFound
+	}
+	return routine, nil
+}
+
+func (store *MemoryStore) Put(routine Routine) error {
+	if err := ValidateName(routine.Name); err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.routines[routine.Owner] == nil {
+		store.routines[routine.Owner] = map[string]Routine{}
+	}
+	store.routines[routine.Owner][routine.Name] = routine
+	return nil
+}
+
+func (store *MemoryStore) Update(owner, name string, update func(routine *Routine, exists bool) error) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	routine, exists := store.routines[owner][name]
+	if !exists {
+		routine = Routine{Owner: owner, Name: name}
+	}
+	if err := update(&routine, exists); err != nil {
+		return err
+	}
+	if routine.Owner != owner || routine.Name != name {
+		return ErrRenamed
+	}
+	if store.routines[owner] == nil {
+		store.routines[owner] = map[string]Routine{}
+	}
+	store.routines[owner][name] = routine
+	return nil
+}
+
+func (store *MemoryStore) Delete(owner, name string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if _, exists := store.routines[owner][name]; !exists {
+		return ErrNotFound
+	}
+	delete(store.routines[owner], name)
+	return nil
+}
+
+// --- File Store ---
+
+// FileStore keeps each routine as a JSON file in a directory per owner.
+// Directory and file names are the base64url encoded owner and routine
+// name, so any name is a safe file name.
+type FileStore struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+// NewFileStore uses dir for routine files, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("creating routine store directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (store *FileStore) ownerDir(owner string) string {
+	return filepath.Join(store.dir, base64.RawURLEncoding.EncodeToString([]byte(owner)))
+}
+
+func (store *FileStore) path(owner, name string) string {
+	return filepath.Join(store.ownerDir(owner), base64.RawURLEncoding.EncodeToString([]byte(name))+".json")
+}
+
+func (store *FileStore) List(owner string) ([]Routine, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	files, err := filepath.Glob(filepath.Join(store.ownerDir(owner), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Routine, 0, len(files))
+	for _, file := range files {
+		routine, err := readRoutineFile(file)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, routine)
+	}
+	sortByName(list)
+	return list, nil
+}
+
+func (store *FileStore) Get(owner, name string) (Routine, error) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	routine, err := readRoutineFile(store.path(owner, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return Routine{}, ErrNotFound
+	}
+	return routine, err
+}
+
+func (store *FileStore) Put(routine Routine) error {
+	if err := ValidateName(routine.Name); err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.write(routine)
+}
+
+func (store *FileStore) Update(owner, name string, update func(routine *Routine, exists bool) error) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	routine, err := readRoutineFile(store.path(owner, name))
+	exists := err == nil
+	if errors.Is(err, os.ErrNotExist) {
+		routine = Routine{Owner: owner, Name: name}
+	} else if err != nil {
+		return err
+	}
+	if err := update(&routine, exists); err != nil {
+		return err
+	}
+	if routine.Owner != owner || routine.Name != name {
+		return ErrRenamed
+	}
+	return store.write(routine)
+}
+
+// write writes the routine to a temporary file and renames it into place,
+// so a crash never leaves a half-written routine. The caller holds mu.
+func (store *FileStore) write(routine Routine) error {
+	data, err := json.MarshalIndent(routine, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(store.ownerDir(routine.Owner), 0o755)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(store.ownerDir(routine.Owner), ".routine-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), store.path(routine.Owner, routine.Name))
+}
+
+func (store *FileStore) Delete(owner, name string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	err := os.Remove(store.path(owner, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func readRoutineFile(path string) (Routine, error) {
+	var routine Routine
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return routine, err
+	}
+	err = json.Unmarshal(data, &routine)
+	if err != nil {
+		return routine, fmt.Errorf("reading %s: %w", filepath.Base(path), err)
+	}
+	return routine, nil
+}
+
+func sortByName(list []Routine) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+}
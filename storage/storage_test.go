@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"tariffCalculator/skills"
+)
+
+func TestUpdate(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		name  string
+		store Store
+	}{
+		{"memory", NewMemoryStore()},
+		{"file", fileStore},
+	} {
+		// Concurrent notes are all kept
+		err := test.store.Update("athlete", "R1", func(routine *Routine, exists bool) error {
+			if exists {
+				t.Errorf("%s: new routine exists", test.name)
+			}
+			routine.Skills = []skills.TrampolineSkill{skills.CommonSkills["barani"]}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: creating: %v", test.name, err)
+		}
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := test.store.Update("athlete", "R1", func(routine *Routine, exists bool) error {
+					routine.Notes = append(routine.Notes, Note{Author: "coach", Text: "Arms"})
+					return nil
+				})
+				if err != nil {
+					t.Errorf("%s: adding a note: %v", test.name, err)
+				}
+			}()
+		}
+		wg.Wait()
+		routine, err := test.store.Get("athlete", "R1")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(routine.Notes) != 20 || len(routine.Skills) != 1 {
+			t.Errorf("%s: %d notes and %d skills, want 20 and 1", test.name, len(routine.Notes), len(routine.Skills))
+		}
+
+		// A failed update saves nothing
+		failed := errors.New("failed")
+		err = test.store.Update("athlete", "R2", func(routine *Routine, exists bool) error { return failed })
+		if !errors.Is(err, failed) {
+			t.Errorf("%s: update error = %v, want %v", test.name, err, failed)
+		}
+		if _, err := test.store.Get("athlete", "R2"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: failed update saved a routine: %v", test.name, err)
+		}
+
+		err = test.store.Update("athlete", "R1", func(routine *Routine, exists bool) error {
+			routine.Name = "R3"
+			return nil
+		})
+		if !errors.Is(err, ErrRenamed) {
+			t.Errorf("%s: renaming update error = %v, want %v", test.name, err, ErrRenamed)
+		}
+	}
+}
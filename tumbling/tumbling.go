@@ -0,0 +1,256 @@
+package tumbling
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"tariffCalculator/skills"
+)
+
+// PassLength is the number of elements in a tumbling pass.
+const PassLength = 8
+
+type ElementKind int
+
+const (
+	Somersault ElementKind = iota
+	RoundOff
+	FlicFlac
+	Whip
+)
+
+var ElementKindName = map[ElementKind]string{
+	Somersault: "Somersault",
+	RoundOff:   "Round-off",
+	FlicFlac:   "Flic-flac",
+	Whip:       "Whip",
+}
+
+func (kind ElementKind) String() string {
+	return ElementKindName[kind]
+}
+
+func (kind ElementKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(kind.String())
+}
+func (kind *ElementKind) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	for k, v := range ElementKindName {
+		if strings.EqualFold(s, v) {
+			*kind = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown tumbling element %q", s)
+}
+
+// Element is one tumbling element. Rotation, TwistDistribution, Shape and
+// Backward use the same units as skills.TrampolineSkill (quarter somersaults
+// and half twists per somersault) and only apply to somersaults.
+type Element struct {
+	Kind              ElementKind  `json:"kind"`
+	Rotation          int          `json:"rotation"`
+	TwistDistribution []int        `json:"twist_distribution"`
+	Shape             skills.Shape `json:"shape"`
+	Backward          bool         `json:"backward"`
+}
+
+// Skill returns the element as a skills.TrampolineSkill from feet, which gives
+// somersaults the trampoline twist totals and FIG notation.
+func (element *Element) Skill() skills.TrampolineSkill {
+	return skills.TrampolineSkill{
+		Rotation:          element.Rotation,
+		TwistDistribution: element.TwistDistribution,
+		TakeoffPosition:   skills.Feet,
+		Shape:             element.Shape,
+		Backward:          element.Backward,
+	}
+}
+
+// Name describes the element, e.g. "Round-off" or "Double Back Pike".
+func (element *Element) Name() string {
+	if element.Kind != Somersault {
+		return element.Kind.String()
+	}
+	skill := element.Skill()
+	prefixes := []string{"", "", "Double ", "Triple ", "Quadruple "}
+	somersaults := element.Rotation / 4
+	name := element.Shape.String()
+	if somersaults < len(prefixes) {
+		direction := "Front"
+		if element.Backward {
+			direction = "Back"
+		}
+		name = prefixes[somersaults] + direction + " " + name
+	}
+	if twist := skill.TotalTwist(); twist > 0 {
+		name += fmt.Sprintf(" %s Twist", formatTurns(twist))
+	}
+	return name
+}
+
+// formatTurns writes half twists as turns: 1 -> "1/2", 2 -> "1", 3 -> "1 1/2".
+func formatTurns(halves int) string {
+	switch {
+	case halves == 1:
+		return "1/2"
+	case halves%2 == 0:
+		return fmt.Sprint(halves / 2)
+	default:
+		return fmt.Sprintf("%d 1/2", halves/2)
+	}
+}
+
+// Direction is the way the athlete is travelling into and out of an element.
+type Direction int
+
+const (
+	Forward Direction = iota
+	Backward
+)
+
+func (direction Direction) String() string {
+	if direction == Backward {
+		return "Backward"
+	}
+	return "Forward"
+}
+
+// entryExit gives the direction an element must be entered in and the
+// direction it leaves the athlete travelling. An odd number of half twists
+// turns a somersault around.
+func (element *Element) entryExit() (Direction, Direction) {
+	switch element.Kind {
+	case RoundOff:
+		return Forward, Backward
+	case FlicFlac, Whip:
+		return Backward, Backward
+	}
+	entry := Forward
+	if element.Backward {
+		entry = Backward
+	}
+	exit := entry
+	skill := element.Skill()
+	if skill.TotalTwist()%2 == 1 {
+		exit = 1 - entry
+	}
+	return entry, exit
+}
+
+// somersaultTable is indexed by the number of completed somersaults.
+//
+// These values, the link element values and the consecutive-somersault bonus
+// have not yet been checked against the difficulty tables of the FIG
+// Tumbling Code of Points; check them before relying on tumbling tariffs.
+var somersaultTable = skills.SomersaultTable{
+	{Label: "Rotation", PerQuarter: 1, PerHalfTwist: 1},
+	{Label: "Single somersault", PerQuarter: 1, PerHalfTwist: 1, Completion: 1, StraightPike: 1, UntwistedOnly: true},
+	{Label: "Double somersault", PerQuarter: 2, PerHalfTwist: 2, Completion: 4, StraightPike: 2},
+	{Label: "Triple somersault", PerQuarter: 3, PerHalfTwist: 3, Completion: 9, StraightPike: 4},
+	{Label: "Quadruple somersault", PerQuarter: 4, PerHalfTwist: 4, Completion: 14, StraightPike: 6},
+}
+
+// Fixed values, in tenths, of the non-somersault elements.
+var linkValues = map[ElementKind]int{
+	RoundOff: 1,
+	FlicFlac: 1,
+	Whip:     2,
+}
+
+// Breakdown calculates an element's tariff. A somersault performed directly
+// out of another somersault earns the consecutive-somersault bonus of 0.1 per
+// somersault it contains.
+func (element *Element) Breakdown(afterSomersault bool) skills.TariffBreakdown {
+	var b skills.BreakdownBuilder
+	if element.Kind != Somersault {
+		b.Add(element.Kind.String(), linkValues[element.Kind])
+		return b.Result()
+	}
+	skill := element.Skill()
+	somersaultTable.Add(&b, &skill)
+	if afterSomersault {
+		b.Add("Consecutive somersault bonus", max(min(element.Rotation/4, len(somersaultTable)-1), 1))
+	}
+	return b.Result()
+}
+
+type ValidatedElement struct {
+	Element
+	Name        string                 `json:"name"`
+	FIGNotation string                 `json:"FIGNotation,omitempty"`
+	Tariff      float64                `json:"tariff"`
+	Breakdown   skills.TariffBreakdown `json:"breakdown"`
+	Counts      bool                   `json:"counts"`
+	Messages    []string               `json:"messages"`
+}
+
+type PassValidationData struct {
+	Elements    []ValidatedElement `json:"elements"`
+	TotalTariff float64            `json:"totalTariff"`
+	Valid       bool               `json:"valid"`
+	Messages    []string           `json:"messages"`
+}
+
+// ValidatePass checks an eight-element pass: the athlete starts running
+// forward, every element must be entered in the direction the previous one
+// left them travelling, somersaults land on feet and the pass finishes with a
+// somersault. Only the first PassLength elements count towards the tariff.
+func ValidatePass(elements []Element) PassValidationData {
+	data := PassValidationData{Elements: make([]ValidatedElement, len(elements)), Valid: true, Messages: []string{}}
+	problem := func(format string, args ...interface{}) {
+		data.Valid = false
+		data.Messages = append(data.Messages, fmt.Sprintf(format, args...))
+	}
+
+	if len(elements) < PassLength {
+		problem("Pass has %d of %d elements", len(elements), PassLength)
+	} else if len(elements) > PassLength {
+		problem("Pass has %d elements, only the first %d count", len(elements), PassLength)
+	}
+
+	travelling := Forward
+	afterSomersault := false
+	for i := range elements {
+		element := ValidatedElement{Element: elements[i], Name: elements[i].Name(), Counts: i < PassLength, Messages: []string{}}
+		if element.Kind == Somersault {
+			skill := element.Skill()
+			if err := skill.Validate(); err != nil {
+				element.Messages = append(element.Messages, "Twist phases: "+err.Error())
+			}
+			if element.Rotation == 0 || element.Rotation%4 != 0 {
+				element.Messages = append(element.Messages, "Somersaults Must Land On Feet")
+			}
+			element.FIGNotation = skill.FIGNotation()
+		}
+
+		entry, exit := element.entryExit()
+		if entry != travelling {
+			element.Messages = append(element.Messages, fmt.Sprintf("Bad Connection: travelling %s, %s needs %s", travelling, element.Name, entry))
+		}
+		travelling = exit
+
+		element.Breakdown = element.Element.Breakdown(afterSomersault)
+		element.Tariff = element.Breakdown.Total
+		afterSomersault = element.Kind == Somersault
+
+		if !element.Counts {
+			element.Messages = append(element.Messages, fmt.Sprintf("Element >%d (No Tariff)", PassLength))
+		} else {
+			data.TotalTariff += element.Tariff
+		}
+		if len(element.Messages) > 0 && element.Counts {
+			problem("%d. %s: %s", i+1, element.Name, strings.Join(element.Messages, " / "))
+		}
+		data.Elements[i] = element
+	}
+
+	if n := min(len(elements), PassLength); n > 0 && elements[n-1].Kind != Somersault {
+		problem("Pass must finish with a somersault")
+	}
+	return data
+}